@@ -48,6 +48,9 @@ diff <(./ytt -f examples/overlay-not-matcher)           examples/overlay-not-mat
 # test json output
 ./ytt -f examples/k8s-adjust-rbac-version -o json > /dev/null
 
+# test toml output
+diff <(printf 'a: 1\nb:\n  c: [1, x]\n' | ./ytt -f- -o toml) <(printf 'a = 1\n\n[b]\nc = [1, "x"]\n')
+
 # test pipe stdin
 diff <(cat examples/k8s-relative-rolling-update/config.yml | ./ytt -f-) examples/k8s-relative-rolling-update/expected.txt
 
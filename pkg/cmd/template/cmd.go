@@ -22,11 +22,13 @@ type TemplateOptions struct {
 	Debug         bool
 	InspectFiles  bool
 	SchemaEnabled bool
+	OverlayDiff   bool
 
 	BulkFilesSourceOpts    BulkFilesSourceOpts
 	RegularFilesSourceOpts RegularFilesSourceOpts
 	FileMarksOpts          FileMarksOpts
 	DataValuesFlags        DataValuesFlags
+	TimeFlags              TimeFlags
 }
 
 type TemplateInput struct {
@@ -34,9 +36,10 @@ type TemplateInput struct {
 }
 
 type TemplateOutput struct {
-	Files  []files.OutputFile
-	DocSet *yamlmeta.DocumentSet
-	Err    error
+	Files       []files.OutputFile
+	DocSet      *yamlmeta.DocumentSet
+	OverlayDiff string
+	Err         error
 }
 
 type FileSource interface {
@@ -67,11 +70,14 @@ func NewCmd(o *TemplateOptions) *cobra.Command {
 	cmd.Flags().BoolVar(&o.Debug, "debug", false, "Enable debug output")
 	cmd.Flags().BoolVar(&o.InspectFiles, "files-inspect", false, "Inspect files")
 	cmd.Flags().BoolVar(&o.SchemaEnabled, "enable-experiment-schema", false, "Enable experimental schema features")
+	cmd.Flags().BoolVar(&o.OverlayDiff, "overlay-diff", false,
+		"Show per document diff for each overlay file instead of final output")
 
 	o.BulkFilesSourceOpts.Set(cmd)
 	o.RegularFilesSourceOpts.Set(cmd)
 	o.FileMarksOpts.Set(cmd)
 	o.DataValuesFlags.Set(cmd)
+	o.TimeFlags.Set(cmd)
 	return cmd
 }
 
@@ -118,10 +124,16 @@ func (o *TemplateOptions) RunWithFiles(in TemplateInput, ui cmdcore.PlainUI) Tem
 		return TemplateOutput{Err: err}
 	}
 
+	timeOpts, err := o.TimeFlags.AsTimeOpts()
+	if err != nil {
+		return TemplateOutput{Err: err}
+	}
+
 	libraryExecutionFactory := workspace.NewLibraryExecutionFactory(ui, workspace.TemplateLoaderOpts{
 		IgnoreUnknownComments:   o.IgnoreUnknownComments,
 		ImplicitMapKeyOverrides: o.ImplicitMapKeyOverrides,
 		StrictYAML:              o.StrictYAML,
+		Time:                    timeOpts,
 	})
 
 	libraryCtx := workspace.LibraryExecutionContext{Current: rootLibrary, Root: rootLibrary}
@@ -163,11 +175,17 @@ func (o *TemplateOptions) RunWithFiles(in TemplateInput, ui cmdcore.PlainUI) Tem
 		}
 	}
 
-	result, err := libraryLoader.Eval(values, libraryValues)
+	evalOpts := workspace.EvalOpts{RecordOverlaySteps: o.OverlayDiff}
+
+	result, err := libraryLoader.EvalWithOpts(values, libraryValues, evalOpts)
 	if err != nil {
 		return TemplateOutput{Err: err}
 	}
 
+	if o.OverlayDiff {
+		return TemplateOutput{OverlayDiff: NewOverlayDiff(result.OverlaySteps).AsString()}
+	}
+
 	return TemplateOutput{Files: result.Files, DocSet: result.DocSet}
 }
 
@@ -172,6 +172,59 @@ nested:
 	}
 }
 
+func TestDataValuesWithFlagsNonStringKeys(t *testing.T) {
+	yamlTplData := []byte(`
+#@ load("@ytt:data", "data")
+values: #@ data.values`)
+
+	expectedYAMLTplData := `values:
+  messages:
+    200: Success
+    404: Gone
+    "500": Error
+  flags:
+    true: "on"
+`
+
+	yamlData := []byte(`
+#@data/values
+---
+messages:
+  200: OK
+  404: Not Found
+  "500": Internal Server Error
+flags:
+  true: "off"
+`)
+
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("tpl.yml", yamlTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("data.yml", yamlData)),
+	})
+
+	ui := cmdcore.NewPlainUI(false)
+	opts := cmdtpl.NewOptions()
+
+	opts.DataValuesFlags = cmdtpl.DataValuesFlags{
+		KVsFromStrings: []string{"messages.200=Success", "messages.404=Gone", "messages.500=Error", "flags.true=on"},
+	}
+
+	out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, ui)
+	if out.Err != nil {
+		t.Fatalf("Expected RunWithFiles to succeed, but was error: %s", out.Err)
+	}
+
+	if len(out.Files) != 1 {
+		t.Fatalf("Expected number of output files to be 1, but was %d", len(out.Files))
+	}
+
+	file := out.Files[0]
+
+	if string(file.Bytes()) != expectedYAMLTplData {
+		t.Fatalf("Expected output file to have specific data, but was: >>>%s<<< vs >>>%s<<<", file.Bytes(), expectedYAMLTplData)
+	}
+}
+
 func TestDataValuesWithLibraryAttachedFlags(t *testing.T) {
 	tplBytes := []byte(`
 #@ load("@ytt:library", "library")
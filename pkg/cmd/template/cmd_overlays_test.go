@@ -4,6 +4,8 @@
 package template_test
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 
 	cmdcore "github.com/k14s/ytt/pkg/cmd/core"
@@ -237,3 +239,549 @@ overlayed: true
 		t.Fatalf("Expected error to match '%s' but was '%s'", expectedErr, out.Err.Error())
 	}
 }
+
+func TestDocumentOverlayCopyAcrossFiles(t *testing.T) {
+	yamlCfgTplData := []byte(`
+kind: ConfigMap
+metadata:
+  name: cfg
+  annotations:
+    owner: team-a
+`)
+
+	yamlDeployTplData := []byte(`
+kind: Deployment
+metadata:
+  name: app
+`)
+
+	yamlOverlayTplData := []byte(`
+#@ load("@ytt:overlay", "overlay")
+#@overlay/match by=overlay.subset({"kind": "Deployment"})
+---
+metadata:
+  #@overlay/match missing_ok=True
+  #@overlay/move from_path="metadata.annotations",from_doc=overlay.subset({"kind": "ConfigMap"})
+  annotations:
+`)
+
+	expectedYAMLTplData := `kind: ConfigMap
+metadata:
+  name: cfg
+---
+kind: Deployment
+metadata:
+  name: app
+  annotations:
+    owner: team-a
+`
+
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("cfg.yml", yamlCfgTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("deploy.yml", yamlDeployTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("overlay.yml", yamlOverlayTplData)),
+	})
+
+	ui := cmdcore.NewPlainUI(false)
+	opts := cmdtpl.NewOptions()
+
+	out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, ui)
+	if out.Err != nil {
+		t.Fatalf("Expected RunWithFiles to succeed, but was error: %s", out.Err)
+	}
+
+	bs, err := out.DocSet.AsBytes()
+	if err != nil {
+		t.Fatalf("Expected marshaling to succeed, but was error: %s", err)
+	}
+
+	if string(bs) != expectedYAMLTplData {
+		t.Fatalf("Expected output to have specific data, but was: >>>%s<<<", bs)
+	}
+}
+
+func TestDocumentOverlayApplyIf(t *testing.T) {
+	yamlTplData := []byte(`
+kind: Deployment
+metadata:
+  name: app
+`)
+
+	yamlOverlayTplData := []byte(`
+#@ load("@ytt:overlay", "overlay")
+
+#@overlay/apply-if exists=overlay.subset({"kind": "Service"})
+#@overlay/match by=overlay.subset({"kind": "Deployment"})
+---
+metadata:
+  #@overlay/match missing_ok=True
+  labels:
+    exposed: true
+
+#@overlay/apply-if via=lambda docs: not any([d["kind"] == "Service" for d in docs])
+#@overlay/match by=overlay.subset({"kind": "Deployment"})
+---
+metadata:
+  #@overlay/match missing_ok=True
+  labels:
+    internal: true
+`)
+
+	expectedYAMLTplData := `kind: Deployment
+metadata:
+  name: app
+  labels:
+    internal: true
+`
+
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("tpl.yml", yamlTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("overlay.yml", yamlOverlayTplData)),
+	})
+
+	ui := cmdcore.NewPlainUI(false)
+	opts := cmdtpl.NewOptions()
+
+	out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, ui)
+	if out.Err != nil {
+		t.Fatalf("Expected RunWithFiles to succeed, but was error: %s", out.Err)
+	}
+
+	bs, err := out.DocSet.AsBytes()
+	if err != nil {
+		t.Fatalf("Expected marshaling to succeed, but was error: %s", err)
+	}
+
+	if string(bs) != expectedYAMLTplData {
+		t.Fatalf("Expected output to have specific data, but was: >>>%s<<<", bs)
+	}
+}
+
+func TestDocumentOverlayDiff(t *testing.T) {
+	yamlTplData := []byte(`
+kind: Service
+metadata:
+  name: svc
+spec:
+  ports:
+  - port: 80
+---
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+
+	yamlOverlayTplData := []byte(`
+#@ load("@ytt:overlay", "overlay")
+
+#@overlay/match by=overlay.subset({"kind": "Service"})
+---
+metadata:
+  #@overlay/match missing_ok=True
+  namespace: prod
+
+#@overlay/match by=overlay.subset({"kind": "ConfigMap"})
+#@overlay/remove
+---
+`)
+
+	yamlOverlay2TplData := []byte(`
+#@ load("@ytt:overlay", "overlay")
+
+#@overlay/match by=overlay.subset({"kind": "Secret"}), expects="0+"
+---
+data: {}
+`)
+
+	expectedDiff := `# overlay overlay.yml
+--- tpl.yml (document 1) (before overlay.yml)
++++ tpl.yml (document 1) (after overlay.yml)
+@@ -1,6 +1,7 @@
+ kind: Service
+ metadata:
+   name: svc
++  namespace: prod
+ spec:
+   ports:
+   - port: 80
+--- tpl.yml (document 2) (before overlay.yml)
++++ /dev/null
+@@ -1,3 +0,0 @@
+-kind: ConfigMap
+-metadata:
+-  name: cm
+# overlay overlay2.yml
+# (no changes)
+`
+
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("tpl.yml", yamlTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("overlay.yml", yamlOverlayTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("overlay2.yml", yamlOverlay2TplData)),
+	})
+
+	ui := cmdcore.NewPlainUI(false)
+	opts := cmdtpl.NewOptions()
+	opts.OverlayDiff = true
+
+	out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, ui)
+	if out.Err != nil {
+		t.Fatalf("Expected RunWithFiles to succeed, but was error: %s", out.Err)
+	}
+
+	if out.OverlayDiff != expectedDiff {
+		t.Fatalf("Expected overlay diff to have specific data, but was: >>>%s<<<", out.OverlayDiff)
+	}
+}
+
+func TestDocumentOverlayStages(t *testing.T) {
+	yamlTplData := []byte(`
+kind: Deployment
+metadata:
+  name: app
+`)
+
+	yamlFinalOverlayTplData := []byte(`
+#@ load("@ytt:overlay", "overlay")
+
+#@overlay/stage name="final", after=["defaults", "default"]
+#@overlay/match by=overlay.subset({"kind": "Deployment"})
+---
+metadata:
+  labels:
+    env: prod
+`)
+
+	yamlDefaultsOverlayTplData := []byte(`
+#@ load("@ytt:overlay", "overlay")
+
+#@overlay/stage name="defaults"
+#@overlay/match by=overlay.subset({"kind": "Deployment"})
+---
+metadata:
+  labels:
+    #@overlay/match missing_ok=True
+    env: dev
+`)
+
+	yamlOverlayTplData := []byte(`
+#@ load("@ytt:overlay", "overlay")
+
+#@overlay/match by=overlay.subset({"kind": "Deployment"})
+---
+metadata:
+  #@overlay/match missing_ok=True
+  labels:
+    team: core
+`)
+
+	expectedYAMLTplData := `kind: Deployment
+metadata:
+  name: app
+  labels:
+    team: core
+    env: prod
+`
+
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("tpl.yml", yamlTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("a-final-overlay.yml", yamlFinalOverlayTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("overlay.yml", yamlOverlayTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("z-defaults-overlay.yml", yamlDefaultsOverlayTplData)),
+	})
+
+	ui := cmdcore.NewPlainUI(false)
+	opts := cmdtpl.NewOptions()
+
+	out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, ui)
+	if out.Err != nil {
+		t.Fatalf("Expected RunWithFiles to succeed, but was error: %s", out.Err)
+	}
+
+	bs, err := out.DocSet.AsBytes()
+	if err != nil {
+		t.Fatalf("Expected marshaling to succeed, but was error: %s", err)
+	}
+
+	if string(bs) != expectedYAMLTplData {
+		t.Fatalf("Expected output to have specific data, but was: >>>%s<<<", bs)
+	}
+}
+
+func TestDocumentOverlayStagesCycleError(t *testing.T) {
+	yamlTplData := []byte(`
+kind: Deployment
+`)
+
+	yamlOverlayTplData := []byte(`
+#@ load("@ytt:overlay", "overlay")
+
+#@overlay/stage name="pre"
+#@overlay/match by=overlay.all
+---
+kind: Deployment
+
+#@overlay/stage name="first", after="third"
+#@overlay/match by=overlay.all
+---
+kind: Deployment
+
+#@overlay/stage name="second", after="first"
+#@overlay/match by=overlay.all
+---
+kind: Deployment
+
+#@overlay/stage name="third", after=["pre", "second"]
+#@overlay/match by=overlay.all
+---
+kind: Deployment
+`)
+
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("tpl.yml", yamlTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("overlay.yml", yamlOverlayTplData)),
+	})
+
+	ui := cmdcore.NewPlainUI(false)
+	opts := cmdtpl.NewOptions()
+
+	out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, ui)
+	if out.Err == nil {
+		t.Fatalf("Expected RunWithFiles to error")
+	}
+
+	expectedErr := "Expected overlay stages to not have cyclic dependencies, " +
+		"but found cycle: first -> third -> second -> first"
+
+	if out.Err.Error() != expectedErr {
+		t.Fatalf("Expected error to match '%s' but was '%s'", expectedErr, out.Err.Error())
+	}
+}
+
+func TestDocumentOverlayLookupAcrossFiles(t *testing.T) {
+	yamlConfigMapTplData := []byte(`
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  key: value
+`)
+
+	yamlDeploymentTplData := []byte(`
+kind: Deployment
+metadata:
+  name: app
+`)
+
+	yamlOverlayTplData := []byte(`
+#@ load("@ytt:overlay", "overlay")
+
+#@ def config_name(left, right):
+#@   return overlay.lookup(overlay.subset({"kind": "ConfigMap"}), expects=1)[0]["metadata"]["name"]
+#@ end
+
+#@overlay/match by=overlay.subset({"kind": "Deployment"})
+---
+metadata:
+  #@overlay/match missing_ok=True
+  labels:
+    #@overlay/match missing_ok=True
+    config: ""
+    #@overlay/match missing_ok=True
+    docs: ""
+
+#@overlay/match by=overlay.subset({"kind": "Deployment"})
+---
+metadata:
+  labels:
+    #@overlay/replace via=config_name
+    config: ""
+    #@overlay/replace via=lambda left, right: str(len(overlay.lookup()))
+    docs: ""
+`)
+
+	expectedYAMLTplData := `kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  key: value
+---
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    config: app-config
+    docs: "2"
+`
+
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("config.yml", yamlConfigMapTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("deployment.yml", yamlDeploymentTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("overlay.yml", yamlOverlayTplData)),
+	})
+
+	ui := cmdcore.NewPlainUI(false)
+	opts := cmdtpl.NewOptions()
+
+	out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, ui)
+	if out.Err != nil {
+		t.Fatalf("Expected RunWithFiles to succeed, but was error: %s", out.Err)
+	}
+
+	bs, err := out.DocSet.AsBytes()
+	if err != nil {
+		t.Fatalf("Expected marshaling to succeed, but was error: %s", err)
+	}
+
+	if string(bs) != expectedYAMLTplData {
+		t.Fatalf("Expected output to have specific data, but was: >>>%s<<<", bs)
+	}
+}
+
+func TestTextOverlays(t *testing.T) {
+	txtTplData := []byte(`[server]
+host = localhost
+debug = true
+# TODO remove
+`)
+
+	yamlTplData := []byte(`
+kind: ConfigMap
+`)
+
+	yamlOverlayTplData := []byte(`
+#@ load("@ytt:overlay", "overlay")
+
+#@overlay/text path="config/*.txt"
+---
+- insert_after: ^\[server\]$
+  text: port = 8080
+- replace: ^debug = (.*)$
+  with: "debug = false (was $1)"
+- remove: ^# TODO
+- insert_before: ^\[client\]$
+  text: "[other]"
+  missing_ok: true
+`)
+
+	expectedTxtData := `[server]
+port = 8080
+host = localhost
+debug = false (was true)
+`
+
+	expectedYAMLTplData := `kind: ConfigMap
+`
+
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("config/app.txt", txtTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("tpl.yml", yamlTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("overlay.yml", yamlOverlayTplData)),
+	})
+
+	ui := cmdcore.NewPlainUI(false)
+	opts := cmdtpl.NewOptions()
+
+	out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, ui)
+	if out.Err != nil {
+		t.Fatalf("Expected RunWithFiles to succeed, but was error: %s", out.Err)
+	}
+
+	if len(out.Files) != 2 {
+		t.Fatalf("Expected number of output files to be 2, but was %d", len(out.Files))
+	}
+
+	file := out.Files[0]
+
+	if file.RelativePath() != "config/app.txt" {
+		t.Fatalf("Expected output file to be config/app.txt, but was %#v", file.RelativePath())
+	}
+
+	if string(file.Bytes()) != expectedTxtData {
+		t.Fatalf("Expected output file to have specific data, but was: >>>%s<<<", file.Bytes())
+	}
+
+	bs, err := out.DocSet.AsBytes()
+	if err != nil {
+		t.Fatalf("Expected marshaling to succeed, but was error: %s", err)
+	}
+
+	if string(bs) != expectedYAMLTplData {
+		t.Fatalf("Expected output to have specific data, but was: >>>%s<<<", bs)
+	}
+}
+
+func TestTextOverlaysPathGlob(t *testing.T) {
+	cases := map[string][]string{
+		// '*' does not match '/' (consistent with data.list(glob=...))
+		"config/*.txt":    {"config/app.txt"},
+		"config/**/*.txt": {"config/app.txt", "config/nested/db.txt"},
+		"**/db.txt":       {"config/nested/db.txt"},
+	}
+
+	for glob, expectedChangedPaths := range cases {
+		yamlOverlayTplData := []byte(fmt.Sprintf(`
+#@overlay/text path="%s"
+---
+- replace: ^debug = true$
+  with: debug = false
+`, glob))
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("config/app.txt", []byte("debug = true\n"))),
+			files.MustNewFileFromSource(files.NewBytesSource("config/nested/db.txt", []byte("debug = true\n"))),
+			files.MustNewFileFromSource(files.NewBytesSource("overlay.yml", yamlOverlayTplData)),
+		})
+
+		ui := cmdcore.NewPlainUI(false)
+		opts := cmdtpl.NewOptions()
+
+		out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, ui)
+		if out.Err != nil {
+			t.Fatalf("Expected RunWithFiles to succeed for glob '%s', but was error: %s", glob, out.Err)
+		}
+
+		var changedPaths []string
+		for _, file := range out.Files {
+			if string(file.Bytes()) == "debug = false\n" {
+				changedPaths = append(changedPaths, file.RelativePath())
+			}
+		}
+
+		if !reflect.DeepEqual(changedPaths, expectedChangedPaths) {
+			t.Fatalf("Expected glob '%s' to change files %#v, but changed %#v", glob, expectedChangedPaths, changedPaths)
+		}
+	}
+}
+
+func TestTextOverlaysNoMatchingLinesError(t *testing.T) {
+	txtTplData := []byte(`debug = true
+`)
+
+	yamlOverlayTplData := []byte(`
+#@overlay/text path="app.txt"
+---
+- remove: ^port =
+`)
+
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("app.txt", txtTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("overlay.yml", yamlOverlayTplData)),
+	})
+
+	ui := cmdcore.NewPlainUI(false)
+	opts := cmdtpl.NewOptions()
+
+	out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, ui)
+	if out.Err == nil {
+		t.Fatalf("Expected RunWithFiles to error")
+	}
+
+	expectedErr := "Overlaying text (overlay.yml): Document on line overlay.yml:3: " +
+		"File 'app.txt': Operation on line overlay.yml:4: " +
+		"Expected 'remove' pattern '^port =' to match at least one line, but did not"
+
+	if out.Err.Error() != expectedErr {
+		t.Fatalf("Expected error to match '%s' but was '%s'", expectedErr, out.Err.Error())
+	}
+}
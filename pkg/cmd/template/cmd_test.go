@@ -293,6 +293,60 @@ libdata2: #@ data.read("/other")
 	}
 }
 
+func TestDataListGlobAndReadBase64(t *testing.T) {
+	yamlTplData := []byte(`
+#@ load("@ytt:data", "data")
+
+all_json: #@ data.list(glob="**/*.json")
+top_json: #@ data.list(glob="config/*.json")
+root_alternatives: #@ data.list("/", glob="**/*.{cfg,der}")
+root_glob: #@ data.list("/", glob="/config/*.json")
+binary: #@ data.read("certs/key.der", encoding="base64")`)
+
+	expectedYAMLTplData := `all_json:
+- app.json
+- config/app.json
+- config/nested/db.json
+top_json:
+- config/app.json
+root_alternatives:
+- /config/app.cfg
+- /certs/key.der
+root_glob:
+- /config/app.json
+binary: AP/+gA==
+`
+
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("tpl.yml", yamlTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("app.json", []byte("{}"))),
+		files.MustNewFileFromSource(files.NewBytesSource("config/app.json", []byte("{}"))),
+		files.MustNewFileFromSource(files.NewBytesSource("config/app.cfg", []byte("cfg"))),
+		files.MustNewFileFromSource(files.NewBytesSource("config/nested/db.json", []byte("{}"))),
+		files.MustNewFileFromSource(files.NewBytesSource("certs/key.der", []byte{0x00, 0xff, 0xfe, 0x80})),
+	})
+
+	ui := cmdcore.NewPlainUI(false)
+	opts := cmdtpl.NewOptions()
+
+	out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, ui)
+	if out.Err != nil {
+		t.Fatalf("Expected RunWithFiles to succeed, but was error: %s", out.Err)
+	}
+
+	if len(out.Files) != 1 {
+		t.Fatalf("Expected number of output files to be 1, but was %d", len(out.Files))
+	}
+
+	file := out.Files[0]
+	if file.RelativePath() != "tpl.yml" {
+		t.Fatalf("Expected output file to be tpl.yml, but was %#v", file.RelativePath())
+	}
+	if string(file.Bytes()) != expectedYAMLTplData {
+		t.Fatalf("Expected output file to have specific data, but was: >>>%s<<<", file.Bytes())
+	}
+}
+
 func TestBacktraceAcrossFiles(t *testing.T) {
 	yamlTplData := []byte(`
 #@ load("funcs/funcs.lib.yml", "some_data")
@@ -338,6 +392,48 @@ simple_key: #@ another_data()
 	}
 }
 
+func TestAssertPositionInStarlarkFile(t *testing.T) {
+	yamlTplData := []byte(`
+#@ load("@ytt:assert", "assert")
+#@ load("funcs/funcs.star", "check")
+try_to: #@ assert.try_to(lambda: check(3))[1]
+simple_key: #@ check(1)
+`)
+
+	starlarkFuncsData := []byte(`
+load("@ytt:assert", "assert")
+def check(val):
+  return assert.max(val, 1, "replicas")
+end
+`)
+
+	expectedYAMLTplData := `try_to: 'assert.max: fail: replicas: expected value 3 to be at most 1 (at funcs/funcs.star:4)'
+simple_key: 1
+`
+
+	filesToProcess := []*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("tpl.yml", yamlTplData)),
+		files.MustNewFileFromSource(files.NewBytesSource("funcs/funcs.star", starlarkFuncsData)),
+	}
+
+	ui := cmdcore.NewPlainUI(false)
+	opts := cmdtpl.NewOptions()
+
+	out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, ui)
+	if out.Err != nil {
+		t.Fatalf("Expected RunWithFiles to succeed, but was error: %s", out.Err)
+	}
+
+	bs, err := out.DocSet.AsBytes()
+	if err != nil {
+		t.Fatalf("Expected marshaling to succeed, but was error: %s", err)
+	}
+
+	if string(bs) != expectedYAMLTplData {
+		t.Fatalf("Expected output to have specific data, but was: >>>%s<<<", bs)
+	}
+}
+
 func TestDisallowDirectLibraryLoading(t *testing.T) {
 	yamlTplData := []byte(`#@ load("_ytt_lib/data.lib.star", "data")`)
 
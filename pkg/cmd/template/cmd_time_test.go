@@ -0,0 +1,130 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package template_test
+
+import (
+	"strings"
+	"testing"
+
+	cmdcore "github.com/k14s/ytt/pkg/cmd/core"
+	cmdtpl "github.com/k14s/ytt/pkg/cmd/template"
+	"github.com/k14s/ytt/pkg/files"
+)
+
+func TestTimeFromFlagAndEnv(t *testing.T) {
+	tmplBytes := []byte(`
+#@ load("@ytt:time", "time")
+#@ load("@ytt:library", "library")
+#@ load("@ytt:template", "template")
+
+now: #@ time.now()
+--- #@ template.replace(library.get("lib").eval())`)
+
+	libTmplBytes := []byte(`
+#@ load("@ytt:time", "time")
+lib_now: #@ time.now()`)
+
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("tpl.yml", tmplBytes)),
+		files.MustNewFileFromSource(files.NewBytesSource("_ytt_lib/lib/tpl.yml", libTmplBytes)),
+	})
+
+	noEnvFunc := func(string) (string, bool) { return "", false }
+	epochEnvFunc := func(name string) (string, bool) {
+		if name == "SOURCE_DATE_EPOCH" {
+			return "1600000000", true
+		}
+		return "", false
+	}
+
+	examples := []struct {
+		Flags    cmdtpl.TimeFlags
+		Expected string
+	}{
+		{
+			Flags:    cmdtpl.TimeFlags{Time: "2021-05-01T10:00:00+02:00", LookupEnvFunc: noEnvFunc},
+			Expected: "now: \"2021-05-01T10:00:00+02:00\"\n---\nlib_now: \"2021-05-01T10:00:00+02:00\"\n",
+		},
+		{
+			Flags:    cmdtpl.TimeFlags{LookupEnvFunc: epochEnvFunc},
+			Expected: "now: \"2020-09-13T12:26:40Z\"\n---\nlib_now: \"2020-09-13T12:26:40Z\"\n",
+		},
+		{
+			// Flag takes precedence over env variable
+			Flags:    cmdtpl.TimeFlags{Time: "2021-05-01T10:00:00Z", LookupEnvFunc: epochEnvFunc},
+			Expected: "now: \"2021-05-01T10:00:00Z\"\n---\nlib_now: \"2021-05-01T10:00:00Z\"\n",
+		},
+		{
+			// Zero time is a valid time (not treated as unset)
+			Flags:    cmdtpl.TimeFlags{Time: "0001-01-01T00:00:00Z", LookupEnvFunc: noEnvFunc},
+			Expected: "now: \"0001-01-01T00:00:00Z\"\n---\nlib_now: \"0001-01-01T00:00:00Z\"\n",
+		},
+		{
+			Flags:    cmdtpl.TimeFlags{LookupEnvFunc: func(string) (string, bool) { return "-62135596800", true }},
+			Expected: "now: \"0001-01-01T00:00:00Z\"\n---\nlib_now: \"0001-01-01T00:00:00Z\"\n",
+		},
+	}
+
+	for _, ex := range examples {
+		opts := cmdtpl.NewOptions()
+		opts.TimeFlags = ex.Flags
+
+		out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, cmdcore.NewPlainUI(false))
+		if out.Err != nil {
+			t.Fatalf("Expected RunWithFiles to succeed, but was error: %s", out.Err)
+		}
+
+		if len(out.Files) != 1 {
+			t.Fatalf("Expected number of output files to be 1, but was %d", len(out.Files))
+		}
+
+		if string(out.Files[0].Bytes()) != ex.Expected {
+			t.Fatalf("Expected output file to have specific data, but was: >>>%s<<<", out.Files[0].Bytes())
+		}
+	}
+}
+
+func TestTimeNotProvided(t *testing.T) {
+	tmplBytes := []byte(`
+#@ load("@ytt:time", "time")
+now: #@ time.now()`)
+
+	filesToProcess := []*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("tpl.yml", tmplBytes)),
+	}
+
+	opts := cmdtpl.NewOptions()
+	opts.TimeFlags = cmdtpl.TimeFlags{LookupEnvFunc: func(string) (string, bool) { return "", false }}
+
+	out := opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, cmdcore.NewPlainUI(false))
+	if out.Err == nil {
+		t.Fatalf("Expected RunWithFiles to fail")
+	}
+
+	if !strings.Contains(out.Err.Error(), "time.now: expected time to be provided via --time flag or SOURCE_DATE_EPOCH env variable") {
+		t.Fatalf("Expected specific error, but was: %s", out.Err)
+	}
+
+	opts.TimeFlags.AllowWallClock = true
+
+	out = opts.RunWithFiles(cmdtpl.TemplateInput{Files: filesToProcess}, cmdcore.NewPlainUI(false))
+	if out.Err != nil {
+		t.Fatalf("Expected RunWithFiles to succeed, but was error: %s", out.Err)
+	}
+}
+
+func TestTimeInvalidFlag(t *testing.T) {
+	opts := cmdtpl.NewOptions()
+	opts.TimeFlags = cmdtpl.TimeFlags{Time: "2021-05-01"}
+
+	out := opts.RunWithFiles(cmdtpl.TemplateInput{}, cmdcore.NewPlainUI(false))
+	if out.Err == nil {
+		t.Fatalf("Expected RunWithFiles to fail")
+	}
+
+	expectedErr := "Parsing --time flag value '2021-05-01' (expected RFC3339 format, e.g. 2020-01-02T15:04:05Z)"
+	if !strings.Contains(out.Err.Error(), expectedErr) {
+		t.Fatalf("Expected specific error, but was: %s", out.Err)
+	}
+}
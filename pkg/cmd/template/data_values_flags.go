@@ -243,18 +243,25 @@ func (s *DataValuesFlags) buildOverlay(keyPieces []string, value interface{}, de
 
 	for _, piece := range keyPieces {
 		newMap := &yamlmeta.Map{}
-		nodeAnns := template.NodeAnnotations{}
+		matchKwargs := []starlark.Tuple{}
 
 		if strings.HasSuffix(piece, missingOkSuffix) {
 			piece = piece[:len(piece)-1]
-			nodeAnns = template.NodeAnnotations{
-				yttoverlay.AnnotationMatch: template.NodeAnnotation{
-					Kwargs: []starlark.Tuple{{
-						starlark.String(yttoverlay.MatchAnnotationKwargMissingOK),
-						starlark.Bool(true),
-					}},
-				},
-			}
+			matchKwargs = append(matchKwargs, starlark.Tuple{
+				starlark.String(yttoverlay.MatchAnnotationKwargMissingOK),
+				starlark.Bool(true),
+			})
+		}
+
+		// Match keys by their text so that non-string keys
+		// (e.g. HTTP status codes such as 200) can be addressed
+		matchKwargs = append(matchKwargs, starlark.Tuple{
+			starlark.String(yttoverlay.MatchAnnotationKwargBy),
+			yttoverlay.MapKeyTextMatcher(piece),
+		})
+
+		nodeAnns := template.NodeAnnotations{
+			yttoverlay.AnnotationMatch: template.NodeAnnotation{Kwargs: matchKwargs},
 		}
 
 		lastMapItem = &yamlmeta.MapItem{Key: piece, Value: newMap, Position: pos}
@@ -0,0 +1,203 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/k14s/ytt/pkg/workspace"
+	yttoverlay "github.com/k14s/ytt/pkg/yttlibrary/overlay"
+)
+
+const (
+	overlayDiffContextLines = 3
+	overlayDiffNullFile     = "/dev/null"
+)
+
+// OverlayDiff renders unified diff of documents
+// for each overlay file in order of application
+type OverlayDiff struct {
+	steps []workspace.OverlayStep
+}
+
+func NewOverlayDiff(steps []workspace.OverlayStep) OverlayDiff {
+	return OverlayDiff{steps}
+}
+
+func (d OverlayDiff) AsString() string {
+	if len(d.steps) == 0 {
+		return "# no overlays found\n"
+	}
+
+	var result strings.Builder
+
+	for _, step := range d.steps {
+		overlayPath := step.OverlayFile.RelativePath()
+
+		if step.Stage != yttoverlay.DefaultStageName {
+			result.WriteString(fmt.Sprintf("# overlay %s (stage %s)\n", overlayPath, step.Stage))
+		} else {
+			result.WriteString(fmt.Sprintf("# overlay %s\n", overlayPath))
+		}
+
+		if len(step.Changes) == 0 {
+			result.WriteString("# (no changes)\n")
+			continue
+		}
+
+		for _, change := range step.Changes {
+			beforeDesc := fmt.Sprintf("%s (before %s)", change.Desc, overlayPath)
+			afterDesc := fmt.Sprintf("%s (after %s)", change.Desc, overlayPath)
+
+			if change.Before == nil {
+				beforeDesc = overlayDiffNullFile
+			}
+			if change.After == nil {
+				afterDesc = overlayDiffNullFile
+			}
+
+			result.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", beforeDesc, afterDesc))
+			result.WriteString(unifiedLineDiff(string(change.Before), string(change.After)))
+		}
+	}
+
+	return result.String()
+}
+
+type lineDiffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// unifiedLineDiff produces diff hunks (without file headers)
+// based on longest common subsequence of lines
+func unifiedLineDiff(before, after string) string {
+	ops := lineDiffOps(splitDiffLines(before), splitDiffLines(after))
+
+	var result strings.Builder
+
+	for start := 0; start < len(ops); {
+		// Find next changed line
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		hunkStart := start - overlayDiffContextLines
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+
+		// Extend hunk while changes are within context distance of each other
+		hunkEnd := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				hunkEnd = i + 1
+			} else if i-hunkEnd >= 2*overlayDiffContextLines {
+				break
+			}
+		}
+		hunkEnd += overlayDiffContextLines
+		if hunkEnd > len(ops) {
+			hunkEnd = len(ops)
+		}
+
+		result.WriteString(hunkHeader(ops, hunkStart, hunkEnd))
+		for _, op := range ops[hunkStart:hunkEnd] {
+			result.WriteString(fmt.Sprintf("%c%s\n", op.kind, op.line))
+		}
+
+		start = hunkEnd
+	}
+
+	return result.String()
+}
+
+func hunkHeader(ops []lineDiffOp, hunkStart, hunkEnd int) string {
+	beforeLine, afterLine := 1, 1
+	for _, op := range ops[:hunkStart] {
+		if op.kind != '+' {
+			beforeLine++
+		}
+		if op.kind != '-' {
+			afterLine++
+		}
+	}
+
+	var beforeCount, afterCount int
+	for _, op := range ops[hunkStart:hunkEnd] {
+		if op.kind != '+' {
+			beforeCount++
+		}
+		if op.kind != '-' {
+			afterCount++
+		}
+	}
+
+	// Empty ranges refer to the line before the range
+	if beforeCount == 0 {
+		beforeLine--
+	}
+	if afterCount == 0 {
+		afterLine--
+	}
+
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", beforeLine, beforeCount, afterLine, afterCount)
+}
+
+func lineDiffOps(before, after []string) []lineDiffOp {
+	// lcs[i][j] is the length of common subsequence of before[i:] and after[j:]
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			switch {
+			case before[i] == after[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var result []lineDiffOp
+	i, j := 0, 0
+
+	for i < len(before) && j < len(after) {
+		switch {
+		case before[i] == after[j]:
+			result = append(result, lineDiffOp{' ', before[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, lineDiffOp{'-', before[i]})
+			i++
+		default:
+			result = append(result, lineDiffOp{'+', after[j]})
+			j++
+		}
+	}
+	for ; i < len(before); i++ {
+		result = append(result, lineDiffOp{'-', before[i]})
+	}
+	for ; j < len(after); j++ {
+		result = append(result, lineDiffOp{'+', after[j]})
+	}
+
+	return result
+}
+
+func splitDiffLines(str string) []string {
+	if len(str) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(str, "\n"), "\n")
+}
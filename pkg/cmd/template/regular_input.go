@@ -16,6 +16,7 @@ import (
 const (
 	regularFilesOutputTypeYAML = "yaml"
 	regularFilesOutputTypeJSON = "json"
+	regularFilesOutputTypeTOML = "toml"
 	regularFilesOutputTypePos  = "pos"
 )
 
@@ -37,7 +38,7 @@ func (s *RegularFilesSourceOpts) Set(cmd *cobra.Command) {
 		"Delete given directory, and then create it with output files")
 	cmd.Flags().StringVar(&s.outputFiles, "output-files", "", "Add output files to given directory")
 
-	cmd.Flags().StringVarP(&s.outputType, "output", "o", regularFilesOutputTypeYAML, "Output type (yaml, json, pos)")
+	cmd.Flags().StringVarP(&s.outputType, "output", "o", regularFilesOutputTypeYAML, "Output type (yaml, json, toml, pos)")
 
 	cmd.Flags().BoolVar(&s.SymlinkAllowOpts.AllowAll, "dangerous-allow-all-symlink-destinations", false,
 		"Symlinks to all destinations are allowed")
@@ -71,6 +72,11 @@ func (s *RegularFilesSource) Output(out TemplateOutput) error {
 		return out.Err
 	}
 
+	if len(out.OverlayDiff) > 0 {
+		s.ui.Printf("%s", out.OverlayDiff)
+		return nil
+	}
+
 	switch {
 	case len(s.opts.outputDir) > 0:
 		return files.NewOutputDirectory(s.opts.outputDir, out.Files, s.ui).Write()
@@ -85,6 +91,8 @@ func (s *RegularFilesSource) Output(out TemplateOutput) error {
 		printerFunc = nil
 	case regularFilesOutputTypeJSON:
 		printerFunc = func(w io.Writer) yamlmeta.DocumentPrinter { return yamlmeta.NewJSONPrinter(w) }
+	case regularFilesOutputTypeTOML:
+		printerFunc = func(w io.Writer) yamlmeta.DocumentPrinter { return yamlmeta.NewTOMLPrinter(w) }
 	case regularFilesOutputTypePos:
 		printerFunc = func(w io.Writer) yamlmeta.DocumentPrinter {
 			return yamlmeta.WrappedFilePositionPrinter{yamlmeta.NewFilePositionPrinter(w)}
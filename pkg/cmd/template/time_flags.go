@@ -0,0 +1,63 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/k14s/ytt/pkg/yttlibrary"
+	"github.com/spf13/cobra"
+)
+
+const (
+	sourceDateEpochEnvVar = "SOURCE_DATE_EPOCH"
+)
+
+type TimeFlags struct {
+	Time           string
+	AllowWallClock bool
+
+	LookupEnvFunc func(string) (string, bool)
+}
+
+func (s *TimeFlags) Set(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&s.Time, "time", "", "Set time returned by time.now() (format: RFC3339, e.g. 2020-01-02T15:04:05Z) "+
+		"(defaults to "+sourceDateEpochEnvVar+" env variable, if set)")
+	cmd.Flags().BoolVar(&s.AllowWallClock, "time-allow-wall-clock", false,
+		"Allow time.now() to return current time if time is not set via --time or "+sourceDateEpochEnvVar)
+}
+
+func (s *TimeFlags) AsTimeOpts() (yttlibrary.TimeOpts, error) {
+	opts := yttlibrary.TimeOpts{AllowWallClock: s.AllowWallClock}
+
+	if len(s.Time) > 0 {
+		now, err := time.Parse(time.RFC3339, s.Time)
+		if err != nil {
+			return opts, fmt.Errorf("Parsing --time flag value '%s' (expected RFC3339 format, e.g. 2020-01-02T15:04:05Z): %s", s.Time, err)
+		}
+		opts.Now = &now
+		return opts, nil
+	}
+
+	lookupEnvFunc := os.LookupEnv
+	if s.LookupEnvFunc != nil {
+		lookupEnvFunc = s.LookupEnvFunc
+	}
+
+	// See https://reproducible-builds.org/specs/source-date-epoch/
+	if epochStr, found := lookupEnvFunc(sourceDateEpochEnvVar); found && len(epochStr) > 0 {
+		epoch, err := strconv.ParseInt(epochStr, 10, 64)
+		if err != nil {
+			return opts, fmt.Errorf("Parsing %s env variable value '%s' (expected number of seconds since Unix epoch): %s",
+				sourceDateEpochEnvVar, epochStr, err)
+		}
+		now := time.Unix(epoch, 0).UTC()
+		opts.Now = &now
+	}
+
+	return opts, nil
+}
@@ -27,6 +27,8 @@ func NewUnknownPosition() *Position {
 
 func (p *Position) SetFile(file string) { p.file = file }
 
+func (p *Position) File() string { return p.file }
+
 func (p *Position) IsKnown() bool { return p != nil && p.known }
 
 func (p *Position) Line() int {
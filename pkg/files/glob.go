@@ -0,0 +1,57 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package files
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CompilePathGlob compiles glob that has to match whole file path.
+// Supported wildcards: '*' and '?' (do not match '/'), '**' (matches
+// any number of directories) and '{a,b}' alternatives.
+func CompilePathGlob(glob string) (*regexp.Regexp, error) {
+	var result strings.Builder
+	var openBraces int
+
+	result.WriteString("^")
+
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			result.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			result.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			result.WriteString("[^/]*")
+		case glob[i] == '?':
+			result.WriteString("[^/]")
+		case glob[i] == '{':
+			result.WriteString("(?:")
+			openBraces++
+		case glob[i] == '}' && openBraces > 0:
+			result.WriteString(")")
+			openBraces--
+		case glob[i] == ',' && openBraces > 0:
+			result.WriteString("|")
+		default:
+			result.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+
+	result.WriteString("$")
+
+	if openBraces > 0 {
+		return nil, fmt.Errorf("Expected glob '%s' to have matching braces", glob)
+	}
+
+	re, err := regexp.Compile(result.String())
+	if err != nil {
+		return nil, fmt.Errorf("Expected '%s' to be a valid glob: %s", glob, err)
+	}
+	return re, nil
+}
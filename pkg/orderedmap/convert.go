@@ -46,6 +46,38 @@ func (c Conversion) asUnorderedStringMaps(object interface{}) interface{} {
 	}
 }
 
+// AsSortedMaps returns object with keys of all maps sorted
+func (c Conversion) AsSortedMaps() interface{} {
+	return c.asSortedMaps(c.Object)
+}
+
+func (c Conversion) asSortedMaps(object interface{}) interface{} {
+	switch typedObj := object.(type) {
+	case *Map:
+		var keys []interface{}
+		typedObj.Iterate(func(k, _ interface{}) {
+			keys = append(keys, k)
+		})
+
+		result := NewMap()
+		for _, key := range c.sortedMapKeys(keys) {
+			val, _ := typedObj.Get(key)
+			result.Set(key, c.asSortedMaps(val))
+		}
+		return result
+
+	case []interface{}:
+		result := []interface{}{}
+		for _, item := range typedObj {
+			result = append(result, c.asSortedMaps(item))
+		}
+		return result
+
+	default:
+		return typedObj
+	}
+}
+
 func (c Conversion) FromUnorderedMaps() interface{} {
 	return c.fromUnorderedMaps(c.Object)
 }
@@ -100,6 +100,8 @@ func (e *CompiledTemplate) Eval(thread *starlark.Thread, loader CompiledTemplate
 
 	globals := make(starlark.StringDict)
 
+	defer setThreadCompiledTemplateLoader(thread, loader)()
+
 	if e.nodes != nil {
 		instructionBindings := map[string]tplcore.StarlarkFunc{
 			// TODO ProgramAST should get rid of set ctx type calls
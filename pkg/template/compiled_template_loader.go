@@ -7,6 +7,11 @@ import (
 	"fmt"
 
 	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/ytt/pkg/filepos"
+)
+
+const (
+	threadCompiledTemplateLoaderKey = "ytt.template.compiled_template_loader_key"
 )
 
 type CompiledTemplateLoader interface {
@@ -36,3 +41,47 @@ func (l NoopCompiledTemplateLoader) Load(
 
 	return nil, fmt.Errorf("Load is not supported")
 }
+
+func setThreadCompiledTemplateLoader(thread *starlark.Thread, loader CompiledTemplateLoader) func() {
+	prevLoader := thread.Local(threadCompiledTemplateLoaderKey)
+	thread.SetLocal(threadCompiledTemplateLoaderKey, loader)
+	return func() { thread.SetLocal(threadCompiledTemplateLoaderKey, prevLoader) }
+}
+
+// CallerPosition returns template position from which currently
+// executing built-in was called; returns unknown position
+// if it cannot be determined (e.g. for generated code)
+func CallerPosition(thread *starlark.Thread) *filepos.Position {
+	// Depth 0 is the built-in itself
+	if thread.CallStackDepth() < 2 {
+		return filepos.NewUnknownPosition()
+	}
+
+	loader, ok := thread.Local(threadCompiledTemplateLoaderKey).(CompiledTemplateLoader)
+	if !ok {
+		return filepos.NewUnknownPosition()
+	}
+
+	pos := thread.CallFrame(1).Pos
+
+	ct, err := loader.FindCompiledTemplate(pos.Filename())
+	if err != nil || pos.Line < 1 {
+		return filepos.NewUnknownPosition()
+	}
+
+	line := ct.CodeAtLine(filepos.NewPosition(int(pos.Line)))
+	if line == nil || line.SourceLine == nil {
+		return filepos.NewUnknownPosition()
+	}
+
+	result := line.SourceLine.Position
+
+	// Source lines of Starlark files (unlike templates)
+	// are not associated with a file name
+	if len(result.File()) == 0 {
+		result = result.DeepCopy()
+		result.SetFile(pos.Filename())
+	}
+
+	return result
+}
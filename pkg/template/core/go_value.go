@@ -91,7 +91,9 @@ func (e GoValue) asStarlarkValue(val interface{}) starlark.Value {
 }
 
 func (e GoValue) dictAsStarlarkValue(val *orderedmap.Map) starlark.Value {
-	if e.opts.MapIsStruct {
+	// Maps with non-string keys (e.g. HTTP status codes)
+	// cannot be represented as structs, hence use dicts
+	if e.opts.MapIsStruct && e.hasOnlyStringKeys(val) {
 		data := orderedmap.NewMap()
 		val.Iterate(func(k, v interface{}) {
 			if keyStr, ok := k.(string); ok {
@@ -110,6 +112,16 @@ func (e GoValue) dictAsStarlarkValue(val *orderedmap.Map) starlark.Value {
 	return result
 }
 
+func (e GoValue) hasOnlyStringKeys(val *orderedmap.Map) bool {
+	result := true
+	val.Iterate(func(k, v interface{}) {
+		if _, ok := k.(string); !ok {
+			result = false
+		}
+	})
+	return result
+}
+
 func (e GoValue) listAsStarlarkValue(val []interface{}) *starlark.List {
 	result := []starlark.Value{}
 	for _, v := range val {
@@ -0,0 +1,330 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package toml encodes and decodes TOML while preserving
+// order of map keys (maps are represented as *orderedmap.Map).
+package toml
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	upstreamtoml "github.com/BurntSushi/toml"
+	"github.com/k14s/ytt/pkg/orderedmap"
+)
+
+var (
+	bareKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// Marshal encodes map as a TOML document. Since TOML requires
+// key/value pairs of a table to precede its sub-tables, keys with
+// map (or array of maps) values are written after all other keys.
+func Marshal(val interface{}) ([]byte, error) {
+	typedMap, ok := val.(*orderedmap.Map)
+	if !ok {
+		return nil, fmt.Errorf("Expected top level value to be a map, but was %T", val)
+	}
+
+	var buf bytes.Buffer
+
+	err := encoder{&buf}.table(nil, typedMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes TOML document into a map
+// (keys are ordered based on their first appearance in the document;
+// date and time values are represented as strings)
+func Unmarshal(data []byte) (*orderedmap.Map, error) {
+	var raw map[string]interface{}
+
+	md, err := upstreamtoml.Decode(string(data), &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	keyOrder := map[string]int{}
+	for i, key := range md.Keys() {
+		keyPath := strings.Join(key, "\x00")
+		if _, found := keyOrder[keyPath]; !found {
+			keyOrder[keyPath] = i
+		}
+	}
+
+	return decoder{keyOrder}.convert(nil, raw).(*orderedmap.Map), nil
+}
+
+type encoder struct {
+	buf *bytes.Buffer
+}
+
+func (e encoder) table(path []string, val *orderedmap.Map) error {
+	var tableKeys []interface{}
+
+	err := val.IterateErr(func(k, v interface{}) error {
+		if e.isTable(v) || e.isArrayOfTables(v) {
+			tableKeys = append(tableKeys, k)
+			return nil
+		}
+
+		keyPath := append(append([]string{}, path...), fmt.Sprintf("%v", k))
+
+		valStr, err := e.inline(keyPath, v)
+		if err != nil {
+			return err
+		}
+
+		e.buf.WriteString(fmt.Sprintf("%s = %s\n", e.key(keyPath[len(keyPath)-1]), valStr))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, k := range tableKeys {
+		keyPath := append(append([]string{}, path...), fmt.Sprintf("%v", k))
+		v, _ := val.Get(k)
+
+		if typedMap, ok := v.(*orderedmap.Map); ok {
+			e.header("[%s]\n", keyPath)
+			err := e.table(keyPath, typedMap)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, item := range v.([]interface{}) {
+			e.header("[[%s]]\n", keyPath)
+			err := e.table(keyPath, item.(*orderedmap.Map))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e encoder) header(format string, path []string) {
+	if e.buf.Len() > 0 {
+		e.buf.WriteString("\n")
+	}
+
+	var keys []string
+	for _, piece := range path {
+		keys = append(keys, e.key(piece))
+	}
+
+	e.buf.WriteString(fmt.Sprintf(format, strings.Join(keys, ".")))
+}
+
+func (e encoder) inline(path []string, val interface{}) (string, error) {
+	switch typedVal := val.(type) {
+	case nil:
+		return "", fmt.Errorf("Expected value at key '%s' to not be null (TOML does not support null values)",
+			strings.Join(path, "."))
+
+	case string:
+		return e.string(typedVal), nil
+
+	case bool:
+		return strconv.FormatBool(typedVal), nil
+
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", typedVal), nil
+
+	case float32:
+		return e.float(float64(typedVal)), nil
+
+	case float64:
+		return e.float(typedVal), nil
+
+	case []interface{}:
+		var items []string
+		for _, item := range typedVal {
+			itemStr, err := e.inline(path, item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, itemStr)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+
+	case *orderedmap.Map:
+		var items []string
+		err := typedVal.IterateErr(func(k, v interface{}) error {
+			keyStr := fmt.Sprintf("%v", k)
+			itemStr, err := e.inline(append(append([]string{}, path...), keyStr), v)
+			if err != nil {
+				return err
+			}
+			items = append(items, fmt.Sprintf("%s = %s", e.key(keyStr), itemStr))
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(items) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+
+	default:
+		return "", fmt.Errorf("Unsupported value of type %T at key '%s'", val, strings.Join(path, "."))
+	}
+}
+
+func (e encoder) isTable(val interface{}) bool {
+	_, ok := val.(*orderedmap.Map)
+	return ok
+}
+
+func (e encoder) isArrayOfTables(val interface{}) bool {
+	typedVal, ok := val.([]interface{})
+	if !ok || len(typedVal) == 0 {
+		return false
+	}
+	for _, item := range typedVal {
+		if !e.isTable(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e encoder) key(key string) string {
+	if bareKeyRegexp.MatchString(key) {
+		return key
+	}
+	return e.string(key)
+}
+
+func (e encoder) string(val string) string {
+	var result strings.Builder
+
+	result.WriteString(`"`)
+
+	for _, r := range val {
+		switch r {
+		case '"':
+			result.WriteString(`\"`)
+		case '\\':
+			result.WriteString(`\\`)
+		case '\b':
+			result.WriteString(`\b`)
+		case '\t':
+			result.WriteString(`\t`)
+		case '\n':
+			result.WriteString(`\n`)
+		case '\f':
+			result.WriteString(`\f`)
+		case '\r':
+			result.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				result.WriteString(fmt.Sprintf(`\u%04X`, r))
+			} else {
+				result.WriteRune(r)
+			}
+		}
+	}
+
+	result.WriteString(`"`)
+
+	return result.String()
+}
+
+func (e encoder) float(val float64) string {
+	switch {
+	case math.IsNaN(val):
+		return "nan"
+	case math.IsInf(val, 1):
+		return "inf"
+	case math.IsInf(val, -1):
+		return "-inf"
+	}
+
+	result := strconv.FormatFloat(val, 'g', -1, 64)
+	if !strings.ContainsAny(result, ".e") {
+		result += ".0"
+	}
+	return result
+}
+
+type decoder struct {
+	keyOrder map[string]int
+}
+
+func (d decoder) convert(path []string, val interface{}) interface{} {
+	switch typedVal := val.(type) {
+	case map[string]interface{}:
+		var keys []string
+		for k := range typedVal {
+			keys = append(keys, k)
+		}
+
+		sort.SliceStable(keys, func(i, j int) bool {
+			iOrder, iFound := d.keyOrder[d.keyPath(path, keys[i])]
+			jOrder, jFound := d.keyOrder[d.keyPath(path, keys[j])]
+			switch {
+			case iFound && jFound:
+				return iOrder < jOrder
+			case iFound != jFound:
+				return iFound
+			default:
+				return keys[i] < keys[j]
+			}
+		})
+
+		result := orderedmap.NewMap()
+		for _, k := range keys {
+			result.Set(k, d.convert(append(append([]string{}, path...), k), typedVal[k]))
+		}
+		return result
+
+	case []map[string]interface{}:
+		// Items of arrays of tables share key paths
+		result := []interface{}{}
+		for _, item := range typedVal {
+			result = append(result, d.convert(path, item))
+		}
+		return result
+
+	case []interface{}:
+		result := []interface{}{}
+		for _, item := range typedVal {
+			result = append(result, d.convert(path, item))
+		}
+		return result
+
+	case time.Time:
+		switch typedVal.Location().String() {
+		case "datetime-local":
+			return typedVal.Format("2006-01-02T15:04:05.999999999")
+		case "date-local":
+			return typedVal.Format("2006-01-02")
+		case "time-local":
+			return typedVal.Format("15:04:05.999999999")
+		default:
+			return typedVal.Format(time.RFC3339Nano)
+		}
+
+	default:
+		return val
+	}
+}
+
+func (d decoder) keyPath(path []string, key string) string {
+	return strings.Join(append(append([]string{}, path...), key), "\x00")
+}
@@ -22,9 +22,14 @@ type LibraryLoader struct {
 }
 
 type EvalResult struct {
-	Files   []files.OutputFile
-	DocSet  *yamlmeta.DocumentSet
-	Exports []EvalExport
+	Files        []files.OutputFile
+	DocSet       *yamlmeta.DocumentSet
+	Exports      []EvalExport
+	OverlaySteps []OverlayStep
+}
+
+type EvalOpts struct {
+	RecordOverlaySteps bool
 }
 
 type EvalExport struct {
@@ -127,20 +132,40 @@ func (ll *LibraryLoader) filesByAnnotation(annName structmeta.AnnotationName, lo
 }
 
 func (ll *LibraryLoader) Eval(values *DataValues, libraryValues []*DataValues) (*EvalResult, error) {
+	return ll.EvalWithOpts(values, libraryValues, EvalOpts{})
+}
+
+func (ll *LibraryLoader) EvalWithOpts(values *DataValues,
+	libraryValues []*DataValues, opts EvalOpts) (*EvalResult, error) {
+
 	exports, docSets, outputFiles, err := ll.eval(values, libraryValues)
 	if err != nil {
 		return nil, err
 	}
 
-	docSets, err = (&OverlayPostProcessing{docSets: docSets}).Apply()
+	outputFiles, err = TextOverlayPostProcessing{
+		docSets:     docSets,
+		outputFiles: outputFiles,
+	}.Apply()
+	if err != nil {
+		return nil, err
+	}
+
+	overlayPostProcessing := &OverlayPostProcessing{
+		docSets:     docSets,
+		recordSteps: opts.RecordOverlaySteps,
+	}
+
+	docSets, err = overlayPostProcessing.Apply()
 	if err != nil {
 		return nil, err
 	}
 
 	result := &EvalResult{
-		Files:   outputFiles,
-		DocSet:  &yamlmeta.DocumentSet{},
-		Exports: exports,
+		Files:        outputFiles,
+		DocSet:       &yamlmeta.DocumentSet{},
+		Exports:      exports,
+		OverlaySteps: overlayPostProcessing.Steps(),
 	}
 
 	for _, fileInLib := range ll.sortedOutputDocSets(docSets) {
@@ -4,6 +4,7 @@
 package workspace
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 
@@ -14,10 +15,31 @@ import (
 )
 
 type OverlayPostProcessing struct {
-	docSets map[*FileInLibrary]*yamlmeta.DocumentSet
+	docSets     map[*FileInLibrary]*yamlmeta.DocumentSet
+	recordSteps bool
+	steps       []OverlayStep
 }
 
-func (o OverlayPostProcessing) Apply() (map[*FileInLibrary]*yamlmeta.DocumentSet, error) {
+// OverlayStep describes how documents changed after applying
+// all overlay documents from a single file within a stage
+type OverlayStep struct {
+	OverlayFile *FileInLibrary
+	Stage       string
+	Changes     []OverlayDocChange
+}
+
+type OverlayDocChange struct {
+	Desc   string
+	Before []byte // nil if document was added
+	After  []byte // nil if document was removed
+}
+
+type overlayDocSnapshot struct {
+	doc   *yamlmeta.Document
+	bytes []byte
+}
+
+func (o *OverlayPostProcessing) Apply() (map[*FileInLibrary]*yamlmeta.DocumentSet, error) {
 	overlayDocSets := map[*FileInLibrary][]*yamlmeta.Document{}
 	docSetsWithoutOverlays := []*yamlmeta.DocumentSet{}
 	docSetToFilesMapping := map[*yamlmeta.DocumentSet]*FileInLibrary{}
@@ -50,8 +72,33 @@ func (o OverlayPostProcessing) Apply() (map[*FileInLibrary]*yamlmeta.DocumentSet
 	}
 	SortFilesInLibrary(sortedOverlayFiles)
 
+	stages := newOverlayStages()
+
 	for _, file := range sortedOverlayFiles {
 		for _, overlay := range overlayDocSets[file] {
+			err := stages.add(file, overlay)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	groups, err := stages.groups()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		var before map[*yamlmeta.DocumentSet][]overlayDocSnapshot
+		if o.recordSteps {
+			var err error
+			before, err = o.snapshot(docSetsWithoutOverlays)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, overlay := range group.docs {
 			op := yttoverlay.OverlayOp{
 				// special case: array of docsets so that file association can be preserved
 				Left: docSetsWithoutOverlays,
@@ -63,10 +110,22 @@ func (o OverlayPostProcessing) Apply() (map[*FileInLibrary]*yamlmeta.DocumentSet
 			newLeft, err := op.Apply()
 			if err != nil {
 				return nil, fmt.Errorf("Overlaying (in following order: %s): %s",
-					o.allFileDescs(sortedOverlayFiles), err)
+					o.allGroupDescs(groups), err)
 			}
 			docSetsWithoutOverlays = newLeft.([]*yamlmeta.DocumentSet)
 		}
+
+		if o.recordSteps {
+			after, err := o.snapshot(docSetsWithoutOverlays)
+			if err != nil {
+				return nil, err
+			}
+			o.steps = append(o.steps, OverlayStep{
+				OverlayFile: group.file,
+				Stage:       group.stage,
+				Changes:     o.changes(docSetsWithoutOverlays, docSetToFilesMapping, before, after),
+			})
+		}
 	}
 
 	result := map[*FileInLibrary]*yamlmeta.DocumentSet{}
@@ -82,10 +141,107 @@ func (o OverlayPostProcessing) Apply() (map[*FileInLibrary]*yamlmeta.DocumentSet
 	return result, nil
 }
 
-func (o OverlayPostProcessing) allFileDescs(files []*FileInLibrary) string {
+// Steps returns changes made by each overlay file (in order of application)
+// if step recording was enabled; otherwise returns nil
+func (o *OverlayPostProcessing) Steps() []OverlayStep { return o.steps }
+
+func (o *OverlayPostProcessing) snapshot(
+	docSets []*yamlmeta.DocumentSet) (map[*yamlmeta.DocumentSet][]overlayDocSnapshot, error) {
+
+	result := map[*yamlmeta.DocumentSet][]overlayDocSnapshot{}
+
+	for _, docSet := range docSets {
+		for _, doc := range docSet.Items {
+			if doc == nil {
+				continue
+			}
+			docBytes, err := (&yamlmeta.DocumentSet{Items: []*yamlmeta.Document{doc}}).AsBytes()
+			if err != nil {
+				return nil, fmt.Errorf("Marshaling document for overlay diff: %s", err)
+			}
+			if docBytes == nil {
+				docBytes = []byte{} // distinguish empty document from missing one
+			}
+			result[docSet] = append(result[docSet], overlayDocSnapshot{doc, docBytes})
+		}
+	}
+
+	return result, nil
+}
+
+// changes pairs up documents by identity; documents that were
+// replaced (hence have new identity) are paired up by their position
+func (o *OverlayPostProcessing) changes(docSets []*yamlmeta.DocumentSet,
+	docSetToFilesMapping map[*yamlmeta.DocumentSet]*FileInLibrary,
+	before, after map[*yamlmeta.DocumentSet][]overlayDocSnapshot) []OverlayDocChange {
+
+	var result []OverlayDocChange
+
+	for _, docSet := range docSets {
+		beforeSnaps := before[docSet]
+		afterSnaps := after[docSet]
+
+		beforeDocs := map[*yamlmeta.Document]int{}
+		for i, snap := range beforeSnaps {
+			beforeDocs[snap.doc] = i
+		}
+		afterDocs := map[*yamlmeta.Document]int{}
+		for i, snap := range afterSnaps {
+			afterDocs[snap.doc] = i
+		}
+
+		pairedBefore := map[int]bool{}
+		desc := func(idx int) string {
+			fileDesc := "?"
+			if file, ok := docSetToFilesMapping[docSet]; ok {
+				fileDesc = file.RelativePath()
+			}
+			return fmt.Sprintf("%s (document %d)", fileDesc, idx+1)
+		}
+
+		for i, afterSnap := range afterSnaps {
+			beforeIdx, found := beforeDocs[afterSnap.doc]
+			if !found && i < len(beforeSnaps) {
+				// Document at the same position was replaced
+				if _, stillPresent := afterDocs[beforeSnaps[i].doc]; !stillPresent && !pairedBefore[i] {
+					beforeIdx, found = i, true
+				}
+			}
+
+			if !found {
+				result = append(result, OverlayDocChange{Desc: desc(i), After: afterSnap.bytes})
+				continue
+			}
+
+			pairedBefore[beforeIdx] = true
+
+			if !bytes.Equal(beforeSnaps[beforeIdx].bytes, afterSnap.bytes) {
+				result = append(result, OverlayDocChange{
+					Desc:   desc(i),
+					Before: beforeSnaps[beforeIdx].bytes,
+					After:  afterSnap.bytes,
+				})
+			}
+		}
+
+		for i, beforeSnap := range beforeSnaps {
+			if !pairedBefore[i] {
+				result = append(result, OverlayDocChange{Desc: desc(i), Before: beforeSnap.bytes})
+			}
+		}
+	}
+
+	return result
+}
+
+func (o *OverlayPostProcessing) allGroupDescs(groups []overlayGroup) string {
 	var result []string
-	for _, fileInLib := range files {
-		result = append(result, fileInLib.File.RelativePath())
+	for _, group := range groups {
+		desc := group.file.File.RelativePath()
+		if group.stage != yttoverlay.DefaultStageName {
+			desc += fmt.Sprintf(" (stage %s)", group.stage)
+		}
+		result = append(result, desc)
 	}
 	return strings.Join(result, ", ")
 }
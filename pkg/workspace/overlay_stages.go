@@ -0,0 +1,178 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package workspace
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/k14s/ytt/pkg/yamlmeta"
+	yttoverlay "github.com/k14s/ytt/pkg/yttlibrary/overlay"
+)
+
+// overlayStages groups overlay documents by stages (specified via
+// @overlay/stage) and orders stages based on their dependencies.
+// Stages without dependencies between them keep order
+// in which they were first seen (i.e. file order).
+type overlayStages struct {
+	stages []*overlayStage
+	byName map[string]*overlayStage
+}
+
+type overlayStage struct {
+	name  string
+	after []string
+	files []*FileInLibrary
+	docs  map[*FileInLibrary][]*yamlmeta.Document
+}
+
+// overlayGroup is a set of overlay documents from
+// a single file that belong to the same stage
+type overlayGroup struct {
+	stage string
+	file  *FileInLibrary
+	docs  []*yamlmeta.Document
+}
+
+func newOverlayStages() *overlayStages {
+	return &overlayStages{byName: map[string]*overlayStage{}}
+}
+
+// add expects documents to be added in file order
+func (s *overlayStages) add(file *FileInLibrary, doc *yamlmeta.Document) error {
+	stageAnn, err := yttoverlay.NewStageAnnotation(doc)
+	if err != nil {
+		return fmt.Errorf("Overlay document (%s): %s", doc.Position.AsCompactString(), err)
+	}
+
+	stage, found := s.byName[stageAnn.Name()]
+	if !found {
+		stage = &overlayStage{
+			name: stageAnn.Name(),
+			docs: map[*FileInLibrary][]*yamlmeta.Document{},
+		}
+		s.stages = append(s.stages, stage)
+		s.byName[stage.name] = stage
+	}
+
+	for _, after := range stageAnn.After() {
+		if !stage.isAfter(after) {
+			stage.after = append(stage.after, after)
+		}
+	}
+
+	if _, found := stage.docs[file]; !found {
+		stage.files = append(stage.files, file)
+	}
+	stage.docs[file] = append(stage.docs[file], doc)
+
+	return nil
+}
+
+// groups returns overlay documents in order they should be applied
+func (s *overlayStages) groups() ([]overlayGroup, error) {
+	sortedStages, err := s.sorted()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []overlayGroup
+
+	for _, stage := range sortedStages {
+		for _, file := range stage.files {
+			result = append(result, overlayGroup{
+				stage: stage.name,
+				file:  file,
+				docs:  stage.docs[file],
+			})
+		}
+	}
+
+	return result, nil
+}
+
+func (s *overlayStages) sorted() ([]*overlayStage, error) {
+	for _, stage := range s.stages {
+		for _, after := range stage.after {
+			if _, found := s.byName[after]; !found {
+				return nil, fmt.Errorf("Expected overlay stage '%s' (referenced by stage '%s') "+
+					"to have at least one overlay document", after, stage.name)
+			}
+		}
+	}
+
+	var result []*overlayStage
+	applied := map[string]bool{}
+
+	for len(result) < len(s.stages) {
+		var next *overlayStage
+
+		// Pick first stage (in order of appearance) with all dependencies applied
+		for _, stage := range s.stages {
+			if !applied[stage.name] && stage.isReady(applied) {
+				next = stage
+				break
+			}
+		}
+
+		if next == nil {
+			return nil, fmt.Errorf("Expected overlay stages to not have cyclic "+
+				"dependencies, but found cycle: %s", s.cycle(applied))
+		}
+
+		result = append(result, next)
+		applied[next.name] = true
+	}
+
+	return result, nil
+}
+
+// cycle follows dependencies between remaining stages until
+// it revisits a stage. Every remaining stage has at least one
+// remaining dependency hence walk is guaranteed to find a cycle.
+func (s *overlayStages) cycle(applied map[string]bool) string {
+	var curr *overlayStage
+	for _, stage := range s.stages {
+		if !applied[stage.name] {
+			curr = stage
+			break
+		}
+	}
+
+	var path []string
+	seenAt := map[string]int{}
+
+	for {
+		if idx, seen := seenAt[curr.name]; seen {
+			return strings.Join(append(path[idx:], curr.name), " -> ")
+		}
+		seenAt[curr.name] = len(path)
+		path = append(path, curr.name)
+
+		for _, after := range curr.after {
+			if !applied[after] {
+				curr = s.byName[after]
+				break
+			}
+		}
+	}
+}
+
+func (s *overlayStage) isAfter(name string) bool {
+	for _, after := range s.after {
+		if after == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *overlayStage) isReady(applied map[string]bool) bool {
+	for _, after := range s.after {
+		if !applied[after] {
+			return false
+		}
+	}
+	return true
+}
@@ -30,6 +30,7 @@ type TemplateLoaderOpts struct {
 	IgnoreUnknownComments   bool
 	ImplicitMapKeyOverrides bool
 	StrictYAML              bool
+	Time                    yttlibrary.TimeOpts
 }
 
 type TemplateLoaderOptsOverrides struct {
@@ -178,6 +179,7 @@ func (l *TemplateLoader) EvalYAML(libraryCtx LibraryExecutionContext, file *file
 
 	yttLibrary := yttlibrary.NewAPI(compiledTemplate.TplReplaceNode,
 		yttlibrary.NewDataModule(l.values.Doc, DataLoader{libraryCtx}),
+		yttlibrary.NewTimeModule(l.opts.Time),
 		NewLibraryModule(libraryCtx, l.libraryExecFactory, l.libraryValuess).AsModule())
 
 	thread := l.newThread(libraryCtx, yttLibrary, file)
@@ -230,6 +232,7 @@ func (l *TemplateLoader) EvalText(libraryCtx LibraryExecutionContext, file *file
 
 	yttLibrary := yttlibrary.NewAPI(compiledTemplate.TplReplaceNode,
 		yttlibrary.NewDataModule(l.values.Doc, DataLoader{libraryCtx}),
+		yttlibrary.NewTimeModule(l.opts.Time),
 		NewLibraryModule(libraryCtx, l.libraryExecFactory, l.libraryValuess).AsModule())
 
 	thread := l.newThread(libraryCtx, yttLibrary, file)
@@ -260,6 +263,7 @@ func (l *TemplateLoader) EvalStarlark(libraryCtx LibraryExecutionContext, file *
 
 	yttLibrary := yttlibrary.NewAPI(compiledTemplate.TplReplaceNode,
 		yttlibrary.NewDataModule(l.values.Doc, DataLoader{libraryCtx}),
+		yttlibrary.NewTimeModule(l.opts.Time),
 		NewLibraryModule(libraryCtx, l.libraryExecFactory, l.libraryValuess).AsModule())
 
 	thread := l.newThread(libraryCtx, yttLibrary, file)
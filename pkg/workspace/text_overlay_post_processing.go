@@ -0,0 +1,87 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package workspace
+
+import (
+	"fmt"
+
+	"github.com/k14s/ytt/pkg/files"
+	"github.com/k14s/ytt/pkg/template"
+	"github.com/k14s/ytt/pkg/yamlmeta"
+	yttoverlay "github.com/k14s/ytt/pkg/yttlibrary/overlay"
+)
+
+// TextOverlayPostProcessing applies @overlay/text documents
+// to text output files; such documents are removed from doc sets
+type TextOverlayPostProcessing struct {
+	docSets     map[*FileInLibrary]*yamlmeta.DocumentSet
+	outputFiles []files.OutputFile
+}
+
+func (o TextOverlayPostProcessing) Apply() ([]files.OutputFile, error) {
+	overlayDocs := map[*FileInLibrary][]*yamlmeta.Document{}
+
+	for file, docSet := range o.docSets {
+		var newItems []*yamlmeta.Document
+		for _, doc := range docSet.Items {
+			if template.NewAnnotations(doc).Has(yttoverlay.AnnotationText) {
+				overlayDocs[file] = append(overlayDocs[file], doc)
+			} else {
+				newItems = append(newItems, doc)
+			}
+		}
+		docSet.Items = newItems
+	}
+
+	var sortedOverlayFiles []*FileInLibrary
+	for file := range overlayDocs {
+		sortedOverlayFiles = append(sortedOverlayFiles, file)
+	}
+	SortFilesInLibrary(sortedOverlayFiles)
+
+	result := append([]files.OutputFile{}, o.outputFiles...)
+
+	for _, file := range sortedOverlayFiles {
+		for _, doc := range overlayDocs[file] {
+			err := o.applyDoc(doc, result)
+			if err != nil {
+				return nil, fmt.Errorf("Overlaying text (%s): Document on %s: %s",
+					file.RelativePath(), doc.Position.AsString(), err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (o TextOverlayPostProcessing) applyDoc(doc *yamlmeta.Document, outputFiles []files.OutputFile) error {
+	textOverlay, err := yttoverlay.NewTextOverlay(doc)
+	if err != nil {
+		return err
+	}
+
+	var matched bool
+
+	for i, outputFile := range outputFiles {
+		if !textOverlay.Matches(outputFile.RelativePath()) {
+			continue
+		}
+
+		matched = true
+
+		newText, err := textOverlay.Apply(string(outputFile.Bytes()))
+		if err != nil {
+			return fmt.Errorf("File '%s': %s", outputFile.RelativePath(), err)
+		}
+
+		outputFiles[i] = files.NewOutputFile(outputFile.RelativePath(), []byte(newText))
+	}
+
+	if !matched {
+		return fmt.Errorf("Expected '%s' annotation to match at least one text file, but did not",
+			yttoverlay.AnnotationText)
+	}
+
+	return nil
+}
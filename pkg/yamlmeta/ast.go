@@ -51,6 +51,7 @@ type Document struct {
 
 	annotations interface{}
 	injected    bool // indicates that Document was not present in the parsed content
+	empty       bool // indicates that Document had no content in the parsed content
 }
 
 type Map struct {
@@ -42,6 +42,7 @@ func (n *Document) DeepCopy() *Document {
 
 		annotations: annotationsDeepCopy(n.annotations),
 		injected:    n.injected,
+		empty:       n.empty,
 	}
 }
 
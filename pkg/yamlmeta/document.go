@@ -21,6 +21,12 @@ func (d *Document) IsEmpty() bool {
 	return false
 }
 
+// HasContent indicates that document is not empty in the parsed content
+// (unlike IsEmpty, documents with null, {} or [] values have content)
+func (d *Document) HasContent() bool {
+	return !d.injected && !d.empty
+}
+
 func (d *Document) AsYAMLBytes() ([]byte, error) {
 	return yaml.Marshal(convertToLowYAML(convertToGo(d.Value)))
 }
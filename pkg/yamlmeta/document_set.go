@@ -55,7 +55,10 @@ func (d *DocumentSet) AsBytesWithPrinter(printerFunc func(io.Writer) DocumentPri
 		if item.injected || item.IsEmpty() {
 			continue
 		}
-		printer.Print(item)
+		err := printer.Print(item)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return buf.Bytes(), nil
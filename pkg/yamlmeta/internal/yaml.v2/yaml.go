@@ -107,6 +107,7 @@ type Decoder struct {
 	useMapSlice           bool
 	parser                *parser
 	lastDocumentStartLine *int
+	lastDocumentEmpty     bool
 	resolveFunc           func(tag string, in string) (string, interface{})
 }
 
@@ -164,6 +165,7 @@ func (dec *Decoder) Decode(v interface{}) (err error) {
 	}
 	docLine := d.doc.line
 	dec.lastDocumentStartLine = &docLine
+	dec.lastDocumentEmpty = isEmptyDocument(node)
 	return nil
 }
 
@@ -174,6 +176,21 @@ func (dec *Decoder) DocumentStartLine() int {
 	panic("document start line is not set")
 }
 
+// DocumentIsEmpty indicates that last decoded document had no content
+// (e.g. '---' followed by another '---'), as opposed to explicit null
+func (dec *Decoder) DocumentIsEmpty() bool {
+	return dec.lastDocumentEmpty
+}
+
+func isEmptyDocument(doc *node) bool {
+	if len(doc.children) != 1 {
+		return false
+	}
+	// Parser produces empty plain scalar for documents without content
+	child := doc.children[0]
+	return child.kind == scalarNode && child.value == "" && child.tag == "" && child.implicit
+}
+
 func (dec *Decoder) Comments() []Comment {
 	var comments []Comment
 	for _, c := range dec.parser.parser.comments {
@@ -289,6 +306,12 @@ func (e *Encoder) Encode(v interface{}) (err error) {
 	return nil
 }
 
+// SetIndent changes the used indentation used when encoding
+// (values outside of 2..9 range are treated as 2).
+func (e *Encoder) SetIndent(spaces int) {
+	yaml_emitter_set_indent(&e.encoder.emitter, spaces)
+}
+
 // Close closes the encoder by writing any remaining data.
 // It does not write a stream terminating string "...".
 func (e *Encoder) Close() (err error) {
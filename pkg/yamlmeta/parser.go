@@ -101,6 +101,7 @@ func (p *Parser) parseBytes(data []byte, lineCorrection int) (*DocumentSet, erro
 			Metas:    lastUnassingedMetas,
 			Value:    p.parse(rawVal, lineCorrection),
 			Position: p.newDocPosition(dec.DocumentStartLine(), lineCorrection, len(docSet.Items) == 0),
+			empty:    dec.DocumentIsEmpty(),
 		}
 
 		allMetas, unassignedMetas := p.assignMetas(doc, dec.Comments(), lineCorrection)
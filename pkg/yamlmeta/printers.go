@@ -4,11 +4,14 @@
 package yamlmeta
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 
 	"github.com/k14s/ytt/pkg/orderedmap"
+	"github.com/k14s/ytt/pkg/toml"
+	"github.com/k14s/ytt/pkg/yamlmeta/internal/yaml.v2"
 )
 
 type DocumentPrinter interface {
@@ -17,13 +20,24 @@ type DocumentPrinter interface {
 
 type YAMLPrinter struct {
 	buf         io.Writer
+	opts        YAMLPrinterOpts
 	writtenOnce bool
 }
 
+type YAMLPrinterOpts struct {
+	// Indent is number of spaces used for indentation (0 means default of 2)
+	Indent   int
+	SortKeys bool
+}
+
 var _ DocumentPrinter = &YAMLPrinter{}
 
 func NewYAMLPrinter(writer io.Writer) *YAMLPrinter {
-	return &YAMLPrinter{writer, false}
+	return NewYAMLPrinterWithOpts(writer, YAMLPrinterOpts{})
+}
+
+func NewYAMLPrinterWithOpts(writer io.Writer, opts YAMLPrinterOpts) *YAMLPrinter {
+	return &YAMLPrinter{buf: writer, opts: opts}
 }
 
 func (p *YAMLPrinter) Print(item *Document) error {
@@ -33,7 +47,7 @@ func (p *YAMLPrinter) Print(item *Document) error {
 		p.writtenOnce = true
 	}
 
-	bs, err := item.AsYAMLBytes()
+	bs, err := p.marshal(item)
 	if err != nil {
 		return fmt.Errorf("marshaling doc: %s", err)
 	}
@@ -41,20 +55,184 @@ func (p *YAMLPrinter) Print(item *Document) error {
 	return nil
 }
 
+func (p *YAMLPrinter) marshal(item *Document) ([]byte, error) {
+	if p.opts == (YAMLPrinterOpts{}) {
+		return item.AsYAMLBytes()
+	}
+
+	val := item.AsInterface()
+	if p.opts.SortKeys {
+		val = orderedmap.Conversion{val}.AsSortedMaps()
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	if p.opts.Indent > 0 {
+		enc.SetIndent(p.opts.Indent)
+	}
+
+	err := enc.Encode(convertToLowYAML(val))
+	if err != nil {
+		return nil, err
+	}
+
+	err = enc.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 type JSONPrinter struct {
-	buf io.Writer
+	buf  io.Writer
+	opts JSONPrinterOpts
+}
+
+type JSONPrinterOpts struct {
+	// Indent is number of spaces used for indentation (0 means compact output)
+	Indent     int
+	SortKeys   bool
+	EscapeHTML bool
 }
 
 var _ DocumentPrinter = &JSONPrinter{}
 
+// NewJSONPrinter returns printer that outputs compact JSON with sorted keys
 func NewJSONPrinter(writer io.Writer) JSONPrinter {
-	return JSONPrinter{writer}
+	return NewJSONPrinterWithOpts(writer, JSONPrinterOpts{SortKeys: true, EscapeHTML: true})
+}
+
+func NewJSONPrinterWithOpts(writer io.Writer, opts JSONPrinterOpts) JSONPrinter {
+	return JSONPrinter{writer, opts}
 }
 
 func (p JSONPrinter) Print(item *Document) error {
 	val := item.AsInterface()
 
-	bs, err := json.Marshal(orderedmap.Conversion{val}.AsUnorderedStringMaps())
+	if p.opts.SortKeys {
+		val = orderedmap.Conversion{val}.AsUnorderedStringMaps()
+	} else {
+		val = orderedJSONValue(val)
+	}
+
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(p.opts.EscapeHTML)
+	if p.opts.Indent > 0 {
+		enc.SetIndent("", fmt.Sprintf("%*s", p.opts.Indent, ""))
+	}
+
+	err := enc.Encode(val)
+	if err != nil {
+		return fmt.Errorf("marshaling doc: %s", err)
+	}
+
+	// Encoder always terminates value with a newline
+	p.buf.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return nil
+}
+
+// orderedJSONMap marshals map keys in their original order
+// (encoding/json always sorts keys of Go maps)
+type orderedJSONMap struct {
+	*orderedmap.Map
+}
+
+var _ json.Marshaler = orderedJSONMap{}
+
+func orderedJSONValue(val interface{}) interface{} {
+	switch typedVal := val.(type) {
+	case *orderedmap.Map:
+		return orderedJSONMap{typedVal}
+
+	case []interface{}:
+		result := []interface{}{}
+		for _, item := range typedVal {
+			result = append(result, orderedJSONValue(item))
+		}
+		return result
+
+	default:
+		return val
+	}
+}
+
+func (m orderedJSONMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("{")
+
+	err := m.IterateErr(func(k, v interface{}) error {
+		keyStr, ok := k.(string)
+		if !ok {
+			return fmt.Errorf("Expected map key to be a string, but was %T", k)
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteString(",")
+		}
+
+		keyBs, err := m.marshalItem(keyStr)
+		if err != nil {
+			return err
+		}
+
+		valBs, err := m.marshalItem(orderedJSONValue(v))
+		if err != nil {
+			return err
+		}
+
+		buf.Write(keyBs)
+		buf.WriteString(":")
+		buf.Write(valBs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString("}")
+
+	return buf.Bytes(), nil
+}
+
+func (orderedJSONMap) marshalItem(val interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	// HTML escaping and indentation are applied by the outer encoder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	err := enc.Encode(val)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+type TOMLPrinter struct {
+	buf         io.Writer
+	writtenOnce bool
+}
+
+var _ DocumentPrinter = &TOMLPrinter{}
+
+func NewTOMLPrinter(writer io.Writer) *TOMLPrinter {
+	return &TOMLPrinter{writer, false}
+}
+
+func (p *TOMLPrinter) Print(item *Document) error {
+	// TOML does not have a notion of multiple documents
+	if p.writtenOnce {
+		return fmt.Errorf("Expected exactly one non-empty document to be output as TOML, but found multiple")
+	}
+	p.writtenOnce = true
+
+	bs, err := toml.Marshal(item.AsInterface())
 	if err != nil {
 		return fmt.Errorf("marshaling doc: %s", err)
 	}
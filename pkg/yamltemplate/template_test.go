@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/k14s/ytt/pkg/orderedmap"
@@ -187,8 +188,10 @@ func (l stdTemplateLoader) FindCompiledTemplate(_ string) (*template.CompiledTem
 }
 
 func (l stdTemplateLoader) Load(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+	now := time.Date(2020, 2, 29, 13, 30, 0, 0, time.UTC)
 	api := yttlibrary.NewAPI(l.compiledTemplate.TplReplaceNode,
-		yttlibrary.NewDataModule(defaultInput(), nil), nil)
+		yttlibrary.NewDataModule(defaultInput(), nil),
+		yttlibrary.NewTimeModule(yttlibrary.TimeOpts{Now: &now}), nil)
 	return api.FindModule(strings.TrimPrefix(module, "@ytt:"))
 }
 
@@ -16,20 +16,26 @@ type API struct {
 }
 
 func NewAPI(replaceNodeFunc tplcore.StarlarkFunc, dataMod DataModule,
-	libraryMod starlark.StringDict) API {
+	timeMod TimeModule, libraryMod starlark.StringDict) API {
 
 	return API{map[string]starlark.StringDict{
 		"assert": AssertAPI,
 		"regexp": RegexpAPI,
+		"math":   MathAPI,
 
 		// Hashes
 		"md5":    MD5API,
+		"sha1":   SHA1API,
 		"sha256": SHA256API,
+		"sha512": SHA512API,
+		"crypto": CryptoAPI,
 
 		// Serializations
 		"base64": Base64API,
 		"json":   JSONAPI,
 		"yaml":   YAMLAPI,
+		"toml":   TOMLAPI,
+		"csv":    CSVAPI,
 		"url":    URLAPI,
 
 		// Templating
@@ -41,8 +47,14 @@ func NewAPI(replaceNodeFunc tplcore.StarlarkFunc, dataMod DataModule,
 		"module":  ModuleAPI,
 		"overlay": overlay.API,
 
+		// Networking
+		"ip": IPAPI,
+
 		// Versioning
 		"version": VersionAPI,
+		"semver":  SemverAPI,
+
+		"time": timeMod.AsModule(),
 
 		"library": libraryMod,
 	}}
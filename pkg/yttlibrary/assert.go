@@ -8,6 +8,8 @@ import (
 
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/k14s/starlark-go/syntax"
+	"github.com/k14s/ytt/pkg/template"
 	"github.com/k14s/ytt/pkg/template/core"
 )
 
@@ -16,7 +18,17 @@ var (
 		"assert": &starlarkstruct.Module{
 			Name: "assert",
 			Members: starlark.StringDict{
-				"fail": starlark.NewBuiltin("assert.fail", core.ErrWrapper(assertModule{}.Fail)),
+				"fail":   starlark.NewBuiltin("assert.fail", core.ErrWrapper(assertModule{}.Fail)),
+				"try_to": starlark.NewBuiltin("assert.try_to", core.ErrWrapper(assertModule{}.TryTo)),
+
+				"equals":   starlark.NewBuiltin("assert.equals", core.ErrWrapper(assertModule{}.Equals)),
+				"min":      starlark.NewBuiltin("assert.min", core.ErrWrapper(assertModule{}.check(assertModule{}.checkMin))),
+				"max":      starlark.NewBuiltin("assert.max", core.ErrWrapper(assertModule{}.check(assertModule{}.checkMax))),
+				"min_len":  starlark.NewBuiltin("assert.min_len", core.ErrWrapper(assertModule{}.check(assertModule{}.checkMinLen))),
+				"max_len":  starlark.NewBuiltin("assert.max_len", core.ErrWrapper(assertModule{}.check(assertModule{}.checkMaxLen))),
+				"one_of":   starlark.NewBuiltin("assert.one_of", core.ErrWrapper(assertModule{}.check(assertModule{}.checkOneOf))),
+				"not_null": starlark.NewBuiltin("assert.not_null", core.ErrWrapper(assertModule{}.NotNull)),
+				"valid":    starlark.NewBuiltin("assert.valid", core.ErrWrapper(assertModule{}.Valid)),
 			},
 		},
 	}
@@ -24,6 +36,10 @@ var (
 
 type assertModule struct{}
 
+// assertCheckFunc returns description of why value does not satisfy
+// given argument (empty if it does) or an error if check cannot be performed
+type assertCheckFunc func(val, arg starlark.Value) (string, error)
+
 func (b assertModule) Fail(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	if args.Len() != 1 {
 		return starlark.None, fmt.Errorf("expected exactly one argument")
@@ -36,3 +52,287 @@ func (b assertModule) Fail(thread *starlark.Thread, f *starlark.Builtin, args st
 
 	return starlark.None, fmt.Errorf("fail: %s", val)
 }
+
+// TryTo calls given function and returns a tuple of its result
+// and an error message (result is None if function failed)
+func (b assertModule) TryTo(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	if _, ok := args.Index(0).(starlark.Callable); !ok {
+		return starlark.None, fmt.Errorf("expected argument to be a function, but was %s", args.Index(0).Type())
+	}
+
+	val, err := starlark.Call(thread, args.Index(0), nil, nil)
+	if err != nil {
+		// Backtrace is not useful as part of an error message
+		if evalErr, ok := err.(*starlark.EvalError); ok {
+			return starlark.Tuple{starlark.None, starlark.String(evalErr.Msg)}, nil
+		}
+		return starlark.Tuple{starlark.None, starlark.String(err.Error())}, nil
+	}
+
+	return starlark.Tuple{val, starlark.None}, nil
+}
+
+func (b assertModule) Equals(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	args, msg, err := b.argsAndMsg(args, kwargs, 2)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	failure, err := b.checkEquals(args.Index(0), args.Index(1))
+	if err != nil {
+		return starlark.None, err
+	}
+	if len(failure) > 0 {
+		return starlark.None, b.failure(thread, msg, failure)
+	}
+
+	return starlark.None, nil
+}
+
+func (b assertModule) NotNull(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	args, msg, err := b.argsAndMsg(args, kwargs, 1)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	failure, err := b.checkNotNull(args.Index(0), starlark.True)
+	if err != nil {
+		return starlark.None, err
+	}
+	if len(failure) > 0 {
+		return starlark.None, b.failure(thread, msg, failure)
+	}
+
+	return args.Index(0), nil
+}
+
+// Valid checks value against a dict of rules (e.g. {"min": 1, "max": 10});
+// rule names match assertion function names. If value is None, only
+// 'not_null' rule applies, hence unset values are allowed unless required.
+func (b assertModule) Valid(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	args, msg, err := b.argsAndMsg(args, kwargs, 2)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	val := args.Index(0)
+
+	rules, ok := args.Index(1).(*starlark.Dict)
+	if !ok {
+		return starlark.None, fmt.Errorf("expected rules to be a dict, but was %s", args.Index(1).Type())
+	}
+
+	checks := map[string]assertCheckFunc{
+		"equals":   b.checkEquals,
+		"min":      b.checkMin,
+		"max":      b.checkMax,
+		"min_len":  b.checkMinLen,
+		"max_len":  b.checkMaxLen,
+		"one_of":   b.checkOneOf,
+		"not_null": b.checkNotNull,
+	}
+
+	for _, rule := range rules.Items() {
+		ruleName, ok := rule.Index(0).(starlark.String)
+		if !ok {
+			return starlark.None, fmt.Errorf("expected rule name to be a string, but was %s", rule.Index(0).Type())
+		}
+
+		checkFunc, found := checks[string(ruleName)]
+		if !found {
+			return starlark.None, fmt.Errorf("unknown rule '%s' (supported rules: "+
+				"equals, min, max, min_len, max_len, one_of, not_null)", string(ruleName))
+		}
+
+		if val == starlark.None && ruleName != "not_null" {
+			continue
+		}
+
+		failure, err := checkFunc(val, rule.Index(1))
+		if err != nil {
+			return starlark.None, fmt.Errorf("rule '%s': %s", string(ruleName), err)
+		}
+		if len(failure) > 0 {
+			return starlark.None, b.failure(thread, msg, failure)
+		}
+	}
+
+	return val, nil
+}
+
+// check builds an assertion function that accepts value and an argument,
+// and returns value if it satisfies the check (e.g. x = assert.min(x, 1))
+func (b assertModule) check(checkFunc assertCheckFunc) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		args, msg, err := b.argsAndMsg(args, kwargs, 2)
+		if err != nil {
+			return starlark.None, err
+		}
+
+		failure, err := checkFunc(args.Index(0), args.Index(1))
+		if err != nil {
+			return starlark.None, err
+		}
+		if len(failure) > 0 {
+			return starlark.None, b.failure(thread, msg, failure)
+		}
+
+		return args.Index(0), nil
+	}
+}
+
+func (b assertModule) checkEquals(val, arg starlark.Value) (string, error) {
+	equal, err := starlark.Equal(val, arg)
+	if err != nil {
+		return "", err
+	}
+	if !equal {
+		return fmt.Sprintf("expected %s to equal %s", val.String(), arg.String()), nil
+	}
+	return "", nil
+}
+
+func (b assertModule) checkMin(val, arg starlark.Value) (string, error) {
+	ok, err := starlark.Compare(syntax.GE, val, arg)
+	if err != nil {
+		return "", fmt.Errorf("comparing value with minimum: %s", err)
+	}
+	if !ok {
+		return fmt.Sprintf("expected value %s to be at least %s", val.String(), arg.String()), nil
+	}
+	return "", nil
+}
+
+func (b assertModule) checkMax(val, arg starlark.Value) (string, error) {
+	ok, err := starlark.Compare(syntax.LE, val, arg)
+	if err != nil {
+		return "", fmt.Errorf("comparing value with maximum: %s", err)
+	}
+	if !ok {
+		return fmt.Sprintf("expected value %s to be at most %s", val.String(), arg.String()), nil
+	}
+	return "", nil
+}
+
+func (b assertModule) checkMinLen(val, arg starlark.Value) (string, error) {
+	length, expectedLength, err := b.lengths(val, arg)
+	if err != nil {
+		return "", err
+	}
+	if length < expectedLength {
+		return fmt.Sprintf("expected length to be at least %d, but was %d", expectedLength, length), nil
+	}
+	return "", nil
+}
+
+func (b assertModule) checkMaxLen(val, arg starlark.Value) (string, error) {
+	length, expectedLength, err := b.lengths(val, arg)
+	if err != nil {
+		return "", err
+	}
+	if length > expectedLength {
+		return fmt.Sprintf("expected length to be at most %d, but was %d", expectedLength, length), nil
+	}
+	return "", nil
+}
+
+func (b assertModule) checkOneOf(val, arg starlark.Value) (string, error) {
+	options, ok := arg.(starlark.Iterable)
+	if !ok {
+		return "", fmt.Errorf("expected options to be a list, but was %s", arg.Type())
+	}
+
+	iter := options.Iterate()
+	defer iter.Done()
+
+	var option starlark.Value
+	for iter.Next(&option) {
+		equal, err := starlark.Equal(val, option)
+		if err != nil {
+			return "", err
+		}
+		if equal {
+			return "", nil
+		}
+	}
+
+	return fmt.Sprintf("expected value %s to be one of %s", val.String(), arg.String()), nil
+}
+
+func (b assertModule) checkNotNull(val, arg starlark.Value) (string, error) {
+	if arg.Truth() && val == starlark.None {
+		return "expected value to not be None", nil
+	}
+	return "", nil
+}
+
+func (b assertModule) lengths(val, arg starlark.Value) (int, int, error) {
+	length := starlark.Len(val)
+	if length < 0 {
+		return 0, 0, fmt.Errorf("expected value to have a length, but was %s", val.Type())
+	}
+
+	expectedLength, err := starlark.AsInt32(arg)
+	if err != nil {
+		return 0, 0, fmt.Errorf("expected length to be an int: %s", err)
+	}
+
+	return length, expectedLength, nil
+}
+
+// argsAndMsg accepts message either as an additional
+// positional argument or as 'msg' keyword argument
+func (b assertModule) argsAndMsg(args starlark.Tuple, kwargs []starlark.Tuple, numArgs int) (starlark.Tuple, string, error) {
+	if args.Len() != numArgs && args.Len() != numArgs+1 {
+		return nil, "", fmt.Errorf("expected exactly %d argument(s) and an optional message", numArgs)
+	}
+
+	var msgVal starlark.Value
+	if args.Len() > numArgs {
+		msgVal = args.Index(numArgs)
+	}
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "msg":
+			if msgVal != nil {
+				return nil, "", fmt.Errorf("expected message to be provided either as an argument or as keyword argument 'msg'")
+			}
+			msgVal = kwarg[1]
+		default:
+			return nil, "", fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
+	var msg string
+
+	if msgVal != nil {
+		val, err := core.NewStarlarkValue(msgVal).AsString()
+		if err != nil {
+			return nil, "", fmt.Errorf("expected message to be a string: %s", err)
+		}
+		msg = val
+	}
+
+	return args[:numArgs], msg, nil
+}
+
+// failure includes template position of the assertion call, since
+// error message may be used without a backtrace (e.g. via assert.try_to)
+func (b assertModule) failure(thread *starlark.Thread, msg, desc string) error {
+	if len(msg) > 0 {
+		desc = msg + ": " + desc
+	}
+
+	pos := template.CallerPosition(thread)
+	if pos.IsKnown() {
+		return fmt.Errorf("fail: %s (at %s)", desc, pos.AsCompactString())
+	}
+	return fmt.Errorf("fail: %s", desc)
+}
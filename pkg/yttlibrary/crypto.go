@@ -0,0 +1,229 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package yttlibrary
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/k14s/ytt/pkg/template/core"
+	"golang.org/x/crypto/blowfish"
+)
+
+// CryptoAPI contains multiple modules so that they
+// could be loaded individually (e.g. load("@ytt:crypto", "hmac"))
+var (
+	CryptoAPI = starlark.StringDict{
+		"hmac": &starlarkstruct.Module{
+			Name: "hmac",
+			Members: starlark.StringDict{
+				"sha1":   starlark.NewBuiltin("hmac.sha1", core.ErrWrapper(hmacModule{sha1.New}.Sum)),
+				"sha256": starlark.NewBuiltin("hmac.sha256", core.ErrWrapper(hmacModule{sha256.New}.Sum)),
+				"sha512": starlark.NewBuiltin("hmac.sha512", core.ErrWrapper(hmacModule{sha512.New}.Sum)),
+			},
+		},
+		"uuid": &starlarkstruct.Module{
+			Name: "uuid",
+			Members: starlark.StringDict{
+				"v5": starlark.NewBuiltin("uuid.v5", core.ErrWrapper(uuidModule{}.V5)),
+			},
+		},
+		"bcrypt": &starlarkstruct.Module{
+			Name: "bcrypt",
+			Members: starlark.StringDict{
+				"hash": starlark.NewBuiltin("bcrypt.hash", core.ErrWrapper(bcryptModule{}.Hash)),
+			},
+		},
+	}
+
+	// Well known namespaces from RFC 4122 (Appendix C)
+	uuidNamespaces = map[string]string{
+		"dns":  "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"url":  "6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+		"oid":  "6ba7b812-9dad-11d1-80b4-00c04fd430c8",
+		"x500": "6ba7b814-9dad-11d1-80b4-00c04fd430c8",
+	}
+)
+
+type hmacModule struct {
+	hashFunc func() hash.Hash
+}
+
+func (b hmacModule) Sum(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 2 {
+		return starlark.None, fmt.Errorf("expected exactly 2 arguments")
+	}
+
+	key, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	msg, err := core.NewStarlarkValue(args.Index(1)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	mac := hmac.New(b.hashFunc, []byte(key))
+	mac.Write([]byte(msg))
+
+	return starlark.String(fmt.Sprintf("%x", mac.Sum(nil))), nil
+}
+
+type uuidModule struct{}
+
+// V5 returns name based UUID (RFC 4122, section 4.3) using SHA-1;
+// namespace is either a UUID or one of 'dns', 'url', 'oid', 'x500'
+func (b uuidModule) V5(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 2 {
+		return starlark.None, fmt.Errorf("expected exactly 2 arguments")
+	}
+
+	namespace, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	name, err := core.NewStarlarkValue(args.Index(1)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	namespaceBs, err := b.parse(namespace)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	h := sha1.New()
+	h.Write(namespaceBs)
+	h.Write([]byte(name))
+
+	uuid := h.Sum(nil)[:16]
+	uuid[6] = (uuid[6] & 0x0f) | 0x50 // version 5
+	uuid[8] = (uuid[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return starlark.String(b.format(uuid)), nil
+}
+
+func (b uuidModule) parse(val string) ([]byte, error) {
+	if wellKnown, found := uuidNamespaces[strings.ToLower(val)]; found {
+		val = wellKnown
+	}
+
+	bs, err := hex.DecodeString(strings.Replace(val, "-", "", -1))
+	if err != nil || len(bs) != 16 || len(val) != 36 {
+		return nil, fmt.Errorf("expected namespace '%s' to be a UUID (e.g. '6ba7b810-9dad-11d1-80b4-00c04fd430c8') "+
+			"or one of 'dns', 'url', 'oid', 'x500'", val)
+	}
+
+	return bs, nil
+}
+
+func (b uuidModule) format(uuid []byte) string {
+	hexStr := hex.EncodeToString(uuid)
+	return strings.Join([]string{hexStr[0:8], hexStr[8:12], hexStr[12:16], hexStr[16:20], hexStr[20:32]}, "-")
+}
+
+const (
+	bcryptSaltLen     = 16
+	bcryptMinCost     = 4
+	bcryptMaxCost     = 31
+	bcryptDefaultCost = 10
+	bcryptMaxPassLen  = 72
+)
+
+var (
+	bcryptEncoding    = base64.NewEncoding("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789").WithPadding(base64.NoPadding)
+	bcryptMagicCipher = []byte("OrpheanBeholderScryDoubt")
+)
+
+type bcryptModule struct{}
+
+// Hash returns bcrypt hash (in '$2a$' format accepted by htpasswd and most
+// bcrypt implementations). Unlike typical bcrypt implementations salt
+// is provided by the caller so that result is reproducible.
+func (b bcryptModule) Hash(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 2 {
+		return starlark.None, fmt.Errorf("expected exactly 2 arguments")
+	}
+
+	password, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	salt, err := core.NewStarlarkValue(args.Index(1)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	cost := bcryptDefaultCost
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "cost":
+			cost, err = starlark.AsInt32(kwarg[1])
+			if err != nil {
+				return starlark.None, fmt.Errorf("expected keyword argument 'cost' to be an int: %s", err)
+			}
+		default:
+			return starlark.None, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
+	if len(password) > bcryptMaxPassLen {
+		return starlark.None, fmt.Errorf("expected password to be at most %d bytes long, but was %d", bcryptMaxPassLen, len(password))
+	}
+	if len(salt) != bcryptSaltLen {
+		return starlark.None, fmt.Errorf("expected salt to be exactly %d bytes long, but was %d", bcryptSaltLen, len(salt))
+	}
+	if cost < bcryptMinCost || cost > bcryptMaxCost {
+		return starlark.None, fmt.Errorf("expected keyword argument 'cost' to be between %d and %d, but was %d", bcryptMinCost, bcryptMaxCost, cost)
+	}
+
+	hash, err := b.hash([]byte(password), []byte(salt), cost)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.String(fmt.Sprintf("$2a$%02d$%s%s", cost, bcryptEncoding.EncodeToString([]byte(salt)), hash)), nil
+}
+
+func (b bcryptModule) hash(password, salt []byte, cost int) (string, error) {
+	// Key includes terminating null byte (as in original implementation)
+	key := append(append([]byte{}, password...), 0)
+
+	c, err := blowfish.NewSaltedCipher(key, salt)
+	if err != nil {
+		return "", err
+	}
+
+	rounds := uint64(1) << uint(cost)
+	for i := uint64(0); i < rounds; i++ {
+		blowfish.ExpandKey(key, c)
+		blowfish.ExpandKey(salt, c)
+	}
+
+	cipherData := append([]byte{}, bcryptMagicCipher...)
+
+	for i := 0; i < len(cipherData); i += 8 {
+		for j := 0; j < 64; j++ {
+			c.Encrypt(cipherData[i:i+8], cipherData[i:i+8])
+		}
+	}
+
+	// Only 23 bytes are encoded for compatibility with other implementations
+	return bcryptEncoding.EncodeToString(cipherData[:23]), nil
+}
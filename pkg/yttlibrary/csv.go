@@ -0,0 +1,263 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package yttlibrary
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/k14s/ytt/pkg/orderedmap"
+	"github.com/k14s/ytt/pkg/template/core"
+	"github.com/k14s/ytt/pkg/yamlmeta"
+)
+
+var (
+	CSVAPI = starlark.StringDict{
+		"csv": &starlarkstruct.Module{
+			Name: "csv",
+			Members: starlark.StringDict{
+				"encode": starlark.NewBuiltin("csv.encode", core.ErrWrapper(csvModule{}.Encode)),
+				"decode": starlark.NewBuiltin("csv.decode", core.ErrWrapper(csvModule{}.Decode)),
+			},
+		},
+	}
+)
+
+type csvModule struct{}
+
+type csvOpts struct {
+	Header    bool
+	Delimiter rune
+}
+
+// Encode accepts list of dicts (columns are ordered by first appearance
+// of keys; header row is included unless header=False) or list of lists
+func (b csvModule) Encode(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	opts, err := b.opts(kwargs)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	val := yamlmeta.NewGoFromAST(core.NewStarlarkValue(args.Index(0)).AsGoValue())
+
+	rows, ok := val.([]interface{})
+	if !ok {
+		return starlark.None, fmt.Errorf("expected argument to be a list of rows, but was %s", args.Index(0).Type())
+	}
+
+	records, err := b.records(rows, opts)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	var buf bytes.Buffer
+
+	writer := csv.NewWriter(&buf)
+	writer.Comma = opts.Delimiter
+
+	err = writer.WriteAll(records)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.String(buf.String()), nil
+}
+
+// Decode returns list of dicts keyed by header row (or list
+// of lists with header=False); all values are strings
+func (b csvModule) Decode(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	opts, err := b.opts(kwargs)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	valEncoded, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	// Spreadsheet programs commonly prefix files with UTF-8 byte order mark
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(valEncoded, "\ufeff")))
+	reader.Comma = opts.Delimiter
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	result := []interface{}{}
+
+	if !opts.Header {
+		for _, record := range records {
+			var row []interface{}
+			for _, field := range record {
+				row = append(row, field)
+			}
+			result = append(result, row)
+		}
+		return core.NewGoValue(result).AsStarlarkValue(), nil
+	}
+
+	if len(records) == 0 {
+		return core.NewGoValue(result).AsStarlarkValue(), nil
+	}
+
+	header := records[0]
+	seenColumns := map[string]struct{}{}
+
+	for _, column := range header {
+		if _, found := seenColumns[column]; found {
+			return starlark.None, fmt.Errorf("expected header to have unique column names, but '%s' appears more than once", column)
+		}
+		seenColumns[column] = struct{}{}
+	}
+
+	for _, record := range records[1:] {
+		row := orderedmap.NewMap()
+		for i, field := range record {
+			row.Set(header[i], field)
+		}
+		result = append(result, row)
+	}
+
+	return core.NewGoValue(result).AsStarlarkValue(), nil
+}
+
+func (b csvModule) records(rows []interface{}, opts csvOpts) ([][]string, error) {
+	var records [][]string
+	var columns []interface{}
+	seenColumns := map[interface{}]struct{}{}
+	var rowsAreMaps bool
+
+	for i, row := range rows {
+		switch typedRow := row.(type) {
+		case *orderedmap.Map:
+			if i > 0 && !rowsAreMaps {
+				return nil, fmt.Errorf("expected all rows to be either dicts or lists, but row %d was a dict", i)
+			}
+			rowsAreMaps = true
+
+			typedRow.Iterate(func(k, _ interface{}) {
+				if _, found := seenColumns[k]; !found {
+					seenColumns[k] = struct{}{}
+					columns = append(columns, k)
+				}
+			})
+
+		case []interface{}:
+			if rowsAreMaps {
+				return nil, fmt.Errorf("expected all rows to be either dicts or lists, but row %d was a list", i)
+			}
+
+			var record []string
+			for j, item := range typedRow {
+				field, err := b.field(item)
+				if err != nil {
+					return nil, fmt.Errorf("row %d, column %d: %s", i, j, err)
+				}
+				record = append(record, field)
+			}
+			records = append(records, record)
+
+		default:
+			return nil, fmt.Errorf("expected row %d to be a dict or a list, but was %T", i, row)
+		}
+	}
+
+	if !rowsAreMaps {
+		return records, nil
+	}
+
+	if opts.Header {
+		var record []string
+		for _, column := range columns {
+			record = append(record, fmt.Sprintf("%v", column))
+		}
+		records = append(records, record)
+	}
+
+	for i, row := range rows {
+		var record []string
+		for _, column := range columns {
+			// Missing columns are left empty
+			item, _ := row.(*orderedmap.Map).Get(column)
+			field, err := b.field(item)
+			if err != nil {
+				return nil, fmt.Errorf("row %d, column '%v': %s", i, column, err)
+			}
+			record = append(record, field)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (b csvModule) field(val interface{}) (string, error) {
+	switch typedVal := val.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return typedVal, nil
+	case bool:
+		return strconv.FormatBool(typedVal), nil
+	case int, int64, uint64:
+		return fmt.Sprintf("%d", typedVal), nil
+	case float64:
+		return strconv.FormatFloat(typedVal, 'g', -1, 64), nil
+	case *orderedmap.Map:
+		return "", fmt.Errorf("expected value to be a string, number, bool or None, but was a dict")
+	case []interface{}:
+		return "", fmt.Errorf("expected value to be a string, number, bool or None, but was a list")
+	default:
+		return "", fmt.Errorf("expected value to be a string, number, bool or None, but was %T", val)
+	}
+}
+
+func (b csvModule) opts(kwargs []starlark.Tuple) (csvOpts, error) {
+	opts := csvOpts{Header: true, Delimiter: ','}
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "header":
+			val, err := core.NewStarlarkValue(kwarg[1]).AsBool()
+			if err != nil {
+				return opts, fmt.Errorf("expected keyword argument 'header' to be a boolean: %s", err)
+			}
+			opts.Header = val
+
+		case "delimiter":
+			val, err := core.NewStarlarkValue(kwarg[1]).AsString()
+			if err != nil {
+				return opts, fmt.Errorf("expected keyword argument 'delimiter' to be a string: %s", err)
+			}
+			if utf8.RuneCountInString(val) != 1 || strings.ContainsAny(val, "\"\r\n") {
+				return opts, fmt.Errorf("expected keyword argument 'delimiter' to be a single character "+
+					"(other than quote or newline), but was '%s'", val)
+			}
+			opts.Delimiter, _ = utf8.DecodeRuneInString(val)
+
+		default:
+			return opts, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
+	return opts, nil
+}
@@ -4,10 +4,14 @@
 package yttlibrary
 
 import (
+	"encoding/base64"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/k14s/ytt/pkg/files"
 	"github.com/k14s/ytt/pkg/template/core"
 	"github.com/k14s/ytt/pkg/yamlmeta"
 )
@@ -41,6 +45,8 @@ func (b DataModule) AsModule() starlark.StringDict {
 	}
 }
 
+// List returns paths of accessible files; paths may be filtered
+// with glob keyword argument (e.g. glob="**/*.json")
 func (b DataModule) List(thread *starlark.Thread, f *starlark.Builtin,
 	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 
@@ -58,6 +64,27 @@ func (b DataModule) List(thread *starlark.Thread, f *starlark.Builtin,
 		path = pathStr
 	}
 
+	var globRegexp *regexp.Regexp
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "glob":
+			glob, err := core.NewStarlarkValue(kwarg[1]).AsString()
+			if err != nil {
+				return starlark.None, fmt.Errorf("expected keyword argument 'glob' to be a string: %s", err)
+			}
+			// Leading '/' is optional since listed paths are matched without it
+			globRegexp, err = files.CompilePathGlob(strings.TrimPrefix(glob, "/"))
+			if err != nil {
+				return starlark.None, err
+			}
+		default:
+			return starlark.None, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
 	paths, err := b.loader.FilePaths(path)
 	if err != nil {
 		return starlark.None, err
@@ -65,11 +92,17 @@ func (b DataModule) List(thread *starlark.Thread, f *starlark.Builtin,
 
 	result := []starlark.Value{}
 	for _, path := range paths {
+		// Paths relative to library root are prefixed with '/'
+		if globRegexp != nil && !globRegexp.MatchString(strings.TrimPrefix(path, "/")) {
+			continue
+		}
 		result = append(result, starlark.String(path))
 	}
 	return starlark.NewList(result), nil
 }
 
+// Read returns file contents as is, or base64 encoded
+// with encoding="base64" (useful for binary files)
 func (b DataModule) Read(thread *starlark.Thread, f *starlark.Builtin,
 	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 
@@ -82,10 +115,34 @@ func (b DataModule) Read(thread *starlark.Thread, f *starlark.Builtin,
 		return starlark.None, err
 	}
 
+	var encodeAsBase64 bool
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "encoding":
+			encoding, err := core.NewStarlarkValue(kwarg[1]).AsString()
+			if err != nil {
+				return starlark.None, fmt.Errorf("expected keyword argument 'encoding' to be a string: %s", err)
+			}
+			if encoding != "base64" {
+				return starlark.None, fmt.Errorf("expected keyword argument 'encoding' to be 'base64', but was '%s'", encoding)
+			}
+			encodeAsBase64 = true
+		default:
+			return starlark.None, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
 	fileBs, err := b.loader.FileData(path)
 	if err != nil {
 		return starlark.None, err
 	}
 
+	if encodeAsBase64 {
+		return starlark.String(base64.StdEncoding.EncodeToString(fileBs)), nil
+	}
+
 	return starlark.String(string(fileBs)), nil
 }
@@ -0,0 +1,390 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package yttlibrary
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"net"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/k14s/starlark-go/syntax"
+	"github.com/k14s/ytt/pkg/template/core"
+)
+
+var (
+	IPAPI = starlark.StringDict{
+		"ip": &starlarkstruct.Module{
+			Name: "ip",
+			Members: starlark.StringDict{
+				"parse_addr": starlark.NewBuiltin("ip.parse_addr", core.ErrWrapper(ipModule{}.ParseAddr)),
+				"parse_cidr": starlark.NewBuiltin("ip.parse_cidr", core.ErrWrapper(ipModule{}.ParseCIDR)),
+				"contains":   starlark.NewBuiltin("ip.contains", core.ErrWrapper(ipModule{}.Contains)),
+				"is_ipv4":    starlark.NewBuiltin("ip.is_ipv4", core.ErrWrapper(ipModule{}.IsIPv4)),
+				"is_ipv6":    starlark.NewBuiltin("ip.is_ipv6", core.ErrWrapper(ipModule{}.IsIPv6)),
+			},
+		},
+	}
+)
+
+type ipModule struct{}
+
+func (b ipModule) ParseAddr(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	addr, err := b.addrArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return addr, nil
+}
+
+func (b ipModule) ParseCIDR(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	cidr, err := b.cidrArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return cidr, nil
+}
+
+func (b ipModule) Contains(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 2 {
+		return starlark.None, fmt.Errorf("expected exactly 2 arguments")
+	}
+
+	cidr, err := b.cidrArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	addr, err := b.addrArg(args.Index(1))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.Bool(cidr.network.Contains(addr.ip)), nil
+}
+
+func (b ipModule) IsIPv4(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	addr, err := b.addrArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.Bool(addr.IsIPv4()), nil
+}
+
+func (b ipModule) IsIPv6(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	addr, err := b.addrArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.Bool(!addr.IsIPv4()), nil
+}
+
+// addrArg accepts either ip.addr value or a string
+func (b ipModule) addrArg(val starlark.Value) (*IPAddrValue, error) {
+	if typedVal, ok := val.(*IPAddrValue); ok {
+		return typedVal, nil
+	}
+
+	str, err := core.NewStarlarkValue(val).AsString()
+	if err != nil {
+		return nil, fmt.Errorf("expected IP address to be a string or ip.addr, but was %s", val.Type())
+	}
+
+	ip := net.ParseIP(str)
+	if ip == nil {
+		return nil, fmt.Errorf("expected '%s' to be a valid IP address", str)
+	}
+
+	return NewIPAddrValue(ip), nil
+}
+
+// cidrArg accepts either ip.cidr value or a string
+func (b ipModule) cidrArg(val starlark.Value) (*IPCIDRValue, error) {
+	if typedVal, ok := val.(*IPCIDRValue); ok {
+		return typedVal, nil
+	}
+
+	str, err := core.NewStarlarkValue(val).AsString()
+	if err != nil {
+		return nil, fmt.Errorf("expected CIDR to be a string or ip.cidr, but was %s", val.Type())
+	}
+
+	_, network, err := net.ParseCIDR(str)
+	if err != nil {
+		return nil, fmt.Errorf("expected '%s' to be a valid CIDR (e.g. 10.0.0.0/16)", str)
+	}
+
+	return NewIPCIDRValue(network), nil
+}
+
+// IPAddrValue represents IPv4 or IPv6 address.
+// Addresses are ordered (IPv4 before IPv6) and converted to strings in YAML.
+type IPAddrValue struct {
+	ip net.IP
+}
+
+var _ starlark.HasAttrs = &IPAddrValue{}
+var _ starlark.Comparable = &IPAddrValue{}
+var _ core.StarlarkValueToGoValueConversion = &IPAddrValue{}
+
+func NewIPAddrValue(ip net.IP) *IPAddrValue {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return &IPAddrValue{ip}
+}
+
+func (v *IPAddrValue) String() string         { return v.ip.String() }
+func (v *IPAddrValue) Type() string           { return "ip.addr" }
+func (v *IPAddrValue) Freeze()                {}
+func (v *IPAddrValue) Truth() starlark.Bool   { return true }
+func (v *IPAddrValue) Hash() (uint32, error)  { return hashBytes(v.ip), nil }
+func (v *IPAddrValue) AsGoValue() interface{} { return v.String() }
+func (v *IPAddrValue) IsIPv4() bool           { return len(v.ip) == net.IPv4len }
+
+func (v *IPAddrValue) CompareSameType(op syntax.Token, y starlark.Value, depth int) (bool, error) {
+	return compareResult(op, v.compare(y.(*IPAddrValue)))
+}
+
+func (v *IPAddrValue) compare(other *IPAddrValue) int {
+	if len(v.ip) != len(other.ip) {
+		return len(v.ip) - len(other.ip)
+	}
+	return bytes.Compare(v.ip, other.ip)
+}
+
+func (v *IPAddrValue) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "string":
+		return noArgsMethod(name, func() starlark.Value { return starlark.String(v.String()) }), nil
+	case "is_ipv4":
+		return noArgsMethod(name, func() starlark.Value { return starlark.Bool(v.IsIPv4()) }), nil
+	case "is_ipv6":
+		return noArgsMethod(name, func() starlark.Value { return starlark.Bool(!v.IsIPv4()) }), nil
+	default:
+		return nil, nil
+	}
+}
+
+func (v *IPAddrValue) AttrNames() []string { return []string{"is_ipv4", "is_ipv6", "string"} }
+
+// IPCIDRValue represents IP network (host bits are cleared).
+// Networks are ordered by their address and then by prefix length.
+type IPCIDRValue struct {
+	network *net.IPNet
+}
+
+var _ starlark.HasAttrs = &IPCIDRValue{}
+var _ starlark.Comparable = &IPCIDRValue{}
+var _ core.StarlarkValueToGoValueConversion = &IPCIDRValue{}
+
+func NewIPCIDRValue(network *net.IPNet) *IPCIDRValue {
+	ip := network.IP
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return &IPCIDRValue{&net.IPNet{IP: ip.Mask(network.Mask), Mask: network.Mask}}
+}
+
+func (v *IPCIDRValue) String() string         { return v.network.String() }
+func (v *IPCIDRValue) Type() string           { return "ip.cidr" }
+func (v *IPCIDRValue) Freeze()                {}
+func (v *IPCIDRValue) Truth() starlark.Bool   { return true }
+func (v *IPCIDRValue) Hash() (uint32, error)  { return hashBytes([]byte(v.String())), nil }
+func (v *IPCIDRValue) AsGoValue() interface{} { return v.String() }
+
+func (v *IPCIDRValue) CompareSameType(op syntax.Token, y starlark.Value, depth int) (bool, error) {
+	other := y.(*IPCIDRValue)
+
+	result := NewIPAddrValue(v.network.IP).compare(NewIPAddrValue(other.network.IP))
+	if result == 0 {
+		ones, _ := v.network.Mask.Size()
+		otherOnes, _ := other.network.Mask.Size()
+		result = ones - otherOnes
+	}
+
+	return compareResult(op, result)
+}
+
+func (v *IPCIDRValue) Attr(name string) (starlark.Value, error) {
+	ones, bits := v.network.Mask.Size()
+
+	switch name {
+	case "string":
+		return noArgsMethod(name, func() starlark.Value { return starlark.String(v.String()) }), nil
+	case "addr":
+		return noArgsMethod(name, func() starlark.Value { return NewIPAddrValue(v.network.IP) }), nil
+	case "prefix_length":
+		return noArgsMethod(name, func() starlark.Value { return starlark.MakeInt(ones) }), nil
+	case "netmask":
+		return noArgsMethod(name, func() starlark.Value { return NewIPAddrValue(net.IP(v.network.Mask)) }), nil
+	case "is_ipv4":
+		return noArgsMethod(name, func() starlark.Value { return starlark.Bool(bits == 8*net.IPv4len) }), nil
+	case "is_ipv6":
+		return noArgsMethod(name, func() starlark.Value { return starlark.Bool(bits != 8*net.IPv4len) }), nil
+	case "subnet":
+		return starlark.NewBuiltin("ip.cidr.subnet", core.ErrWrapper(v.subnet)), nil
+	case "host":
+		return starlark.NewBuiltin("ip.cidr.host", core.ErrWrapper(v.host)), nil
+	case "contains":
+		return starlark.NewBuiltin("ip.cidr.contains", core.ErrWrapper(v.contains)), nil
+	default:
+		return nil, nil
+	}
+}
+
+func (v *IPCIDRValue) AttrNames() []string {
+	return []string{"addr", "contains", "host", "is_ipv4", "is_ipv6", "netmask", "prefix_length", "string", "subnet"}
+}
+
+// subnet calculates subnet address within this network
+// given additional prefix bits and network number
+// (e.g. 10.0.0.0/16 with newbits=8 and netnum=2 is 10.0.2.0/24)
+func (v *IPCIDRValue) subnet(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 2 {
+		return starlark.None, fmt.Errorf("expected exactly 2 arguments (newbits, netnum)")
+	}
+
+	newBits, err := core.NewStarlarkValue(args.Index(0)).AsInt64()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	netNum, err := core.NewStarlarkValue(args.Index(1)).AsInt64()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	ones, bits := v.network.Mask.Size()
+
+	if newBits < 0 || int64(ones)+newBits > int64(bits) {
+		return starlark.None, fmt.Errorf("expected newbits to be between 0 and %d, but was %d", bits-ones, newBits)
+	}
+
+	newOnes := ones + int(newBits)
+	maxNetNum := new(big.Int).Lsh(big.NewInt(1), uint(newBits))
+
+	if netNum < 0 || big.NewInt(netNum).Cmp(maxNetNum) >= 0 {
+		return starlark.None, fmt.Errorf("expected netnum to be between 0 and %s, but was %d",
+			new(big.Int).Sub(maxNetNum, big.NewInt(1)), netNum)
+	}
+
+	offset := new(big.Int).Lsh(big.NewInt(netNum), uint(bits-newOnes))
+	ip := ipFromInt(new(big.Int).Add(ipToInt(v.network.IP), offset), len(v.network.IP))
+
+	return NewIPCIDRValue(&net.IPNet{IP: ip, Mask: net.CIDRMask(newOnes, bits)}), nil
+}
+
+// host calculates address of nth host within this network;
+// negative numbers count from the end of the network (-1 is the last address)
+func (v *IPCIDRValue) host(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	hostNum, err := core.NewStarlarkValue(args.Index(0)).AsInt64()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	ones, bits := v.network.Mask.Size()
+	size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+
+	num := big.NewInt(hostNum)
+	if hostNum < 0 {
+		num.Add(num, size)
+	}
+
+	if num.Sign() < 0 || num.Cmp(size) >= 0 {
+		return starlark.None, fmt.Errorf("expected host number %d to be within network '%s' "+
+			"(which has %s addresses)", hostNum, v.String(), size)
+	}
+
+	ip := ipFromInt(new(big.Int).Add(ipToInt(v.network.IP), num), len(v.network.IP))
+
+	return NewIPAddrValue(ip), nil
+}
+
+func (v *IPCIDRValue) contains(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	addr, err := ipModule{}.addrArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.Bool(v.network.Contains(addr.ip)), nil
+}
+
+func noArgsMethod(name string, valFunc func() starlark.Value) *starlark.Builtin {
+	return starlark.NewBuiltin(name, func(thread *starlark.Thread, f *starlark.Builtin,
+		args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+		if args.Len() != 0 || len(kwargs) != 0 {
+			return starlark.None, fmt.Errorf("%s: expected no arguments", name)
+		}
+		return valFunc(), nil
+	})
+}
+
+func compareResult(op syntax.Token, result int) (bool, error) {
+	switch op {
+	case syntax.EQL:
+		return result == 0, nil
+	case syntax.NEQ:
+		return result != 0, nil
+	case syntax.LT:
+		return result < 0, nil
+	case syntax.LE:
+		return result <= 0, nil
+	case syntax.GT:
+		return result > 0, nil
+	case syntax.GE:
+		return result >= 0, nil
+	default:
+		return false, fmt.Errorf("unsupported comparison operator %s", op)
+	}
+}
+
+func hashBytes(data []byte) uint32 {
+	h := fnv.New32a()
+	h.Write(data)
+	return h.Sum32()
+}
+
+func ipToInt(ip net.IP) *big.Int {
+	return new(big.Int).SetBytes(ip)
+}
+
+func ipFromInt(val *big.Int, length int) net.IP {
+	valBytes := val.Bytes()
+	ip := make(net.IP, length)
+	copy(ip[length-len(valBytes):], valBytes)
+	return ip
+}
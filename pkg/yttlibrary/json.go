@@ -4,6 +4,7 @@
 package yttlibrary
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
@@ -33,15 +34,62 @@ func (b jsonModule) Encode(thread *starlark.Thread, f *starlark.Builtin, args st
 		return starlark.None, fmt.Errorf("expected exactly one argument")
 	}
 
+	opts, err := b.encodeOpts(kwargs)
+	if err != nil {
+		return starlark.None, err
+	}
+
 	val := core.NewStarlarkValue(args.Index(0)).AsGoValue()
-	val = orderedmap.Conversion{yamlmeta.NewGoFromAST(val)}.AsUnorderedStringMaps()
 
-	valBs, err := json.Marshal(val)
+	var buf bytes.Buffer
+
+	err = yamlmeta.NewJSONPrinterWithOpts(&buf, opts).Print(&yamlmeta.Document{Value: val})
 	if err != nil {
 		return starlark.None, err
 	}
 
-	return starlark.String(string(valBs)), nil
+	return starlark.String(buf.String()), nil
+}
+
+// encodeOpts defaults to compact output with sorted keys
+// (same as JSON produced by '--output json')
+func (b jsonModule) encodeOpts(kwargs []starlark.Tuple) (yamlmeta.JSONPrinterOpts, error) {
+	opts := yamlmeta.JSONPrinterOpts{SortKeys: true, EscapeHTML: true}
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "indent":
+			indent, err := starlark.AsInt32(kwarg[1])
+			if err != nil {
+				return opts, fmt.Errorf("expected keyword argument 'indent' to be an int: %s", err)
+			}
+			if indent < 0 || indent > 9 {
+				return opts, fmt.Errorf("expected keyword argument 'indent' to be between 0 and 9, but was %d", indent)
+			}
+			opts.Indent = indent
+
+		case "sort_keys":
+			val, err := core.NewStarlarkValue(kwarg[1]).AsBool()
+			if err != nil {
+				return opts, fmt.Errorf("expected keyword argument 'sort_keys' to be a boolean: %s", err)
+			}
+			opts.SortKeys = val
+
+		case "escape_html":
+			val, err := core.NewStarlarkValue(kwarg[1]).AsBool()
+			if err != nil {
+				return opts, fmt.Errorf("expected keyword argument 'escape_html' to be a boolean: %s", err)
+			}
+			opts.EscapeHTML = val
+
+		default:
+			return opts, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
+	return opts, nil
 }
 
 func (b jsonModule) Decode(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -0,0 +1,393 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package yttlibrary
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/k14s/ytt/pkg/template/core"
+)
+
+var (
+	MathAPI = starlark.StringDict{
+		"math": &starlarkstruct.Module{
+			Name: "math",
+			Members: starlark.StringDict{
+				"pi":  starlark.Float(math.Pi),
+				"e":   starlark.Float(math.E),
+				"inf": starlark.Float(math.Inf(1)),
+
+				"ceil":  starlark.NewBuiltin("math.ceil", core.ErrWrapper(mathModule{}.Ceil)),
+				"floor": starlark.NewBuiltin("math.floor", core.ErrWrapper(mathModule{}.Floor)),
+				"trunc": starlark.NewBuiltin("math.trunc", core.ErrWrapper(mathModule{}.Trunc)),
+				"round": starlark.NewBuiltin("math.round", core.ErrWrapper(mathModule{}.Round)),
+				"abs":   starlark.NewBuiltin("math.abs", core.ErrWrapper(mathModule{}.Abs)),
+
+				"pow":   starlark.NewBuiltin("math.pow", core.ErrWrapper(mathModule{}.Pow)),
+				"sqrt":  starlark.NewBuiltin("math.sqrt", core.ErrWrapper(mathModule{}.Sqrt)),
+				"exp":   starlark.NewBuiltin("math.exp", core.ErrWrapper(mathModule{}.Exp)),
+				"log":   starlark.NewBuiltin("math.log", core.ErrWrapper(mathModule{}.Log)),
+				"log2":  starlark.NewBuiltin("math.log2", core.ErrWrapper(mathModule{}.Log2)),
+				"log10": starlark.NewBuiltin("math.log10", core.ErrWrapper(mathModule{}.Log10)),
+
+				"div_ceil":   starlark.NewBuiltin("math.div_ceil", core.ErrWrapper(mathModule{}.DivCeil)),
+				"round_up":   starlark.NewBuiltin("math.round_up", core.ErrWrapper(mathModule{}.RoundUp)),
+				"round_down": starlark.NewBuiltin("math.round_down", core.ErrWrapper(mathModule{}.RoundDown)),
+
+				"is_nan": starlark.NewBuiltin("math.is_nan", core.ErrWrapper(mathModule{}.IsNaN)),
+				"is_inf": starlark.NewBuiltin("math.is_inf", core.ErrWrapper(mathModule{}.IsInf)),
+			},
+		},
+	}
+)
+
+type mathModule struct{}
+
+func (b mathModule) Ceil(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return b.toInt(args, math.Ceil)
+}
+
+func (b mathModule) Floor(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return b.toInt(args, math.Floor)
+}
+
+func (b mathModule) Trunc(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return b.toInt(args, math.Trunc)
+}
+
+// Round rounds half away from zero (e.g. 2.5 to 3, -2.5 to -3).
+// Without ndigits result is an int; with ndigits result is a float
+// rounded based on decimal representation of a value (e.g. 2.675 to 2.68)
+func (b mathModule) Round(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	var ndigits starlark.Value = starlark.None
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "ndigits":
+			ndigits = kwarg[1]
+		default:
+			return starlark.None, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
+	if ndigits == starlark.None {
+		return b.toInt(args, math.Round)
+	}
+
+	digits, err := starlark.AsInt32(ndigits)
+	if err != nil {
+		return starlark.None, fmt.Errorf("expected keyword argument 'ndigits' to be an int: %s", err)
+	}
+	if digits < 0 {
+		return starlark.None, fmt.Errorf("expected keyword argument 'ndigits' to be non-negative, but was %d", digits)
+	}
+
+	val, err := b.floatArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return starlark.Float(val), nil
+	}
+
+	// Shortest decimal representation is used so that
+	// values are rounded the way they are written
+	rat, ok := new(big.Rat).SetString(strconv.FormatFloat(val, 'g', -1, 64))
+	if !ok {
+		return starlark.None, fmt.Errorf("expected float to be representable as decimal")
+	}
+
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil))
+	rat.Mul(rat, scale)
+
+	rounded := b.roundRat(rat)
+	result, _ := new(big.Rat).Quo(new(big.Rat).SetInt(rounded), scale).Float64()
+
+	return starlark.Float(result), nil
+}
+
+func (b mathModule) Abs(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	switch typedVal := args.Index(0).(type) {
+	case starlark.Int:
+		return starlark.MakeBigInt(new(big.Int).Abs(typedVal.BigInt())), nil
+	case starlark.Float:
+		return starlark.Float(math.Abs(float64(typedVal))), nil
+	default:
+		return starlark.None, fmt.Errorf("expected argument to be a number, but was %s", typedVal.Type())
+	}
+}
+
+// Pow returns an exact int when both arguments are ints
+// (and exponent is non-negative); otherwise it returns a float
+func (b mathModule) Pow(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 2 {
+		return starlark.None, fmt.Errorf("expected exactly 2 arguments")
+	}
+
+	base, baseIsInt := args.Index(0).(starlark.Int)
+	exp, expIsInt := args.Index(1).(starlark.Int)
+
+	if baseIsInt && expIsInt && exp.Sign() >= 0 {
+		return starlark.MakeBigInt(new(big.Int).Exp(base.BigInt(), exp.BigInt(), nil)), nil
+	}
+
+	baseVal, err := b.floatArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+	expVal, err := b.floatArg(args.Index(1))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.Float(math.Pow(baseVal, expVal)), nil
+}
+
+func (b mathModule) Sqrt(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	val, err := b.floatArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+	if val < 0 {
+		return starlark.None, fmt.Errorf("expected argument to be non-negative, but was %s", args.Index(0).String())
+	}
+
+	return starlark.Float(math.Sqrt(val)), nil
+}
+
+func (b mathModule) Exp(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	val, err := b.floatArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.Float(math.Exp(val)), nil
+}
+
+// Log returns natural logarithm of a value, or logarithm
+// in a given base if base keyword argument is specified
+func (b mathModule) Log(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	var base starlark.Value = starlark.None
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "base":
+			base = kwarg[1]
+		default:
+			return starlark.None, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
+	if base == starlark.None {
+		return b.log(args, math.Log)
+	}
+
+	baseVal, err := b.floatArg(base)
+	if err != nil {
+		return starlark.None, fmt.Errorf("expected keyword argument 'base' to be a number: %s", err)
+	}
+	if baseVal <= 0 || baseVal == 1 {
+		return starlark.None, fmt.Errorf("expected keyword argument 'base' to be positive and not 1, but was %s", base.String())
+	}
+
+	return b.log(args, func(x float64) float64 { return math.Log(x) / math.Log(baseVal) })
+}
+
+func (b mathModule) Log2(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return b.log(args, math.Log2)
+}
+
+func (b mathModule) Log10(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return b.log(args, math.Log10)
+}
+
+// DivCeil divides two ints rounding result towards positive infinity
+// (complements Starlark's // operator which rounds towards negative infinity)
+func (b mathModule) DivCeil(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	x, y, err := b.intArgs(args)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.MakeBigInt(b.divCeil(x, y)), nil
+}
+
+// RoundUp rounds int up to the nearest multiple of a given int
+// (e.g. math.round_up(1500, 1024) is 2048)
+func (b mathModule) RoundUp(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	x, multiple, err := b.intArgs(args)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.MakeBigInt(new(big.Int).Mul(b.divCeil(x, multiple), multiple)), nil
+}
+
+// RoundDown rounds int down to the nearest multiple of a given int
+// (e.g. math.round_down(1500, 1024) is 1024)
+func (b mathModule) RoundDown(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	x, multiple, err := b.intArgs(args)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	quo := b.divFloor(x, multiple)
+
+	return starlark.MakeBigInt(quo.Mul(quo, multiple)), nil
+}
+
+func (b mathModule) IsNaN(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	val, err := b.floatArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.Bool(math.IsNaN(val)), nil
+}
+
+func (b mathModule) IsInf(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	val, err := b.floatArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.Bool(math.IsInf(val, 0)), nil
+}
+
+// toInt applies rounding function to a float and returns an int;
+// ints are returned as is to avoid losing precision
+func (b mathModule) toInt(args starlark.Tuple, roundFunc func(float64) float64) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	if typedVal, ok := args.Index(0).(starlark.Int); ok {
+		return typedVal, nil
+	}
+
+	val, err := b.floatArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.NumberToInt(starlark.Float(roundFunc(val)))
+}
+
+func (b mathModule) log(args starlark.Tuple, logFunc func(float64) float64) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	val, err := b.floatArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+	if val <= 0 {
+		return starlark.None, fmt.Errorf("expected argument to be positive, but was %s", args.Index(0).String())
+	}
+
+	return starlark.Float(logFunc(val)), nil
+}
+
+func (b mathModule) floatArg(val starlark.Value) (float64, error) {
+	switch typedVal := val.(type) {
+	case starlark.Int:
+		return float64(typedVal.Float()), nil
+	case starlark.Float:
+		return float64(typedVal), nil
+	default:
+		return 0, fmt.Errorf("expected argument to be a number, but was %s", val.Type())
+	}
+}
+
+func (b mathModule) intArgs(args starlark.Tuple) (*big.Int, *big.Int, error) {
+	if args.Len() != 2 {
+		return nil, nil, fmt.Errorf("expected exactly 2 arguments")
+	}
+
+	var result []*big.Int
+
+	for _, arg := range args {
+		typedArg, ok := arg.(starlark.Int)
+		if !ok {
+			return nil, nil, fmt.Errorf("expected arguments to be ints, but was %s", arg.Type())
+		}
+		result = append(result, typedArg.BigInt())
+	}
+
+	if result[1].Sign() == 0 {
+		return nil, nil, fmt.Errorf("expected second argument to be non-zero")
+	}
+
+	return result[0], result[1], nil
+}
+
+func (b mathModule) divFloor(x, y *big.Int) *big.Int {
+	quo, rem := new(big.Int).QuoRem(x, y, new(big.Int))
+	if rem.Sign() != 0 && (rem.Sign() < 0) != (y.Sign() < 0) {
+		quo.Sub(quo, big.NewInt(1))
+	}
+	return quo
+}
+
+func (b mathModule) divCeil(x, y *big.Int) *big.Int {
+	quo, rem := new(big.Int).QuoRem(x, y, new(big.Int))
+	if rem.Sign() != 0 && (rem.Sign() < 0) == (y.Sign() < 0) {
+		quo.Add(quo, big.NewInt(1))
+	}
+	return quo
+}
+
+// roundRat rounds half away from zero
+func (b mathModule) roundRat(val *big.Rat) *big.Int {
+	twice := new(big.Rat).Mul(val, big.NewRat(2, 1))
+	twice.Abs(twice)
+
+	// floor((2*|x| + 1) / 2)
+	num := new(big.Int).Add(twice.Num(), twice.Denom())
+	result := new(big.Int).Quo(num, new(big.Int).Mul(twice.Denom(), big.NewInt(2)))
+
+	if val.Sign() < 0 {
+		result.Neg(result)
+	}
+	return result
+}
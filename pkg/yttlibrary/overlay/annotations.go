@@ -20,9 +20,15 @@ const (
 	AnnotationInsert  structmeta.AnnotationName = "overlay/insert" // array only
 	AnnotationAppend  structmeta.AnnotationName = "overlay/append" // array only
 	AnnotationAssert  structmeta.AnnotationName = "overlay/assert"
+	AnnotationCopy    structmeta.AnnotationName = "overlay/copy"
+	AnnotationMove    structmeta.AnnotationName = "overlay/move"
 
 	AnnotationMatch              structmeta.AnnotationName = "overlay/match"
 	AnnotationMatchChildDefaults structmeta.AnnotationName = "overlay/match-child-defaults"
+	AnnotationApplyIf            structmeta.AnnotationName = "overlay/apply-if" // document only
+	AnnotationStage              structmeta.AnnotationName = "overlay/stage"    // document only
+	AnnotationMergeStrategy      structmeta.AnnotationName = "overlay/merge-strategy"
+	AnnotationText               structmeta.AnnotationName = "overlay/text" // document only
 )
 
 var (
@@ -33,6 +39,8 @@ var (
 		AnnotationInsert,
 		AnnotationAppend,
 		AnnotationAssert,
+		AnnotationCopy,
+		AnnotationMove,
 	}
 )
 
@@ -5,7 +5,7 @@ package overlay
 
 import (
 	"fmt"
-	"reflect"
+	"regexp"
 
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/k14s/starlark-go/starlarkstruct"
@@ -24,6 +24,12 @@ var (
 				"all":     starlark.NewBuiltin("overlay.all", core.ErrWrapper(overlayModule{}.All)),
 				"map_key": overlayModule{}.MapKey(),
 				"subset":  starlark.NewBuiltin("overlay.subset", core.ErrWrapper(overlayModule{}.Subset)),
+				"lookup":  starlark.NewBuiltin("overlay.lookup", core.ErrWrapper(overlayModule{}.Lookup)),
+
+				"assert_that": overlayModule{}.AssertThat(),
+
+				"regexp_subset": starlark.NewBuiltin("overlay.regexp_subset", core.ErrWrapper(overlayModule{}.RegexpSubset)),
+				"glob_subset":   starlark.NewBuiltin("overlay.glob_subset", core.ErrWrapper(overlayModule{}.GlobSubset)),
 
 				"and_op": starlark.NewBuiltin("overlay.and_op", core.ErrWrapper(overlayModule{}.AndOp)),
 				"or_op":  starlark.NewBuiltin("overlay.or_op", core.ErrWrapper(overlayModule{}.OrOp)),
@@ -43,17 +49,62 @@ func (b overlayModule) Apply(
 		return starlark.None, fmt.Errorf("expected exactly at least argument")
 	}
 
+	var exact, report bool
+	strictMissing := true
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		var dest *bool
+
+		switch kwargName {
+		case "exact":
+			dest = &exact
+		case "strict_missing":
+			dest = &strictMissing
+		case "report":
+			dest = &report
+		default:
+			return starlark.None, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+
+		val, err := core.NewStarlarkValue(kwarg[1]).AsBool()
+		if err != nil {
+			return starlark.None, fmt.Errorf("Expected keyword argument '%s' to be a boolean: %s", kwargName, err)
+		}
+		*dest = val
+	}
+
 	typedVals := core.NewStarlarkValue(args).AsGoValue().([]interface{})
 	var result interface{} = typedVals[0]
+	var opReport *Report
+
+	if report {
+		opReport = &Report{}
+	}
 
 	for _, right := range typedVals[1:] {
+		op := OverlayOp{
+			Left:   result,
+			Right:  right,
+			Thread: thread,
+
+			ExactMatch:   exact,
+			AllowMissing: !strictMissing,
+			Report:       opReport,
+		}
+
 		var err error
-		result, err = OverlayOp{Left: result, Right: right, Thread: thread}.Apply() // left is modified
+		result, err = op.Apply() // left is modified
 		if err != nil {
 			return starlark.None, err
 		}
 	}
 
+	if report {
+		return opReport.AsStarlarkValue(yamltemplate.NewStarlarkFragment(result)), nil
+	}
+
 	return yamltemplate.NewStarlarkFragment(result), nil
 }
 
@@ -115,7 +166,14 @@ func (b overlayModule) mapKey(
 		return starlark.None, fmt.Errorf("expected exactly one argument")
 	}
 
-	keyName, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if !isMapKeyName(args.Index(0)) {
+		return starlark.None, fmt.Errorf("expected key name to be a string, int, float or bool, "+
+			"but was %s", args.Index(0).Type())
+	}
+
+	keyName := core.NewStarlarkValue(args.Index(0)).AsGoValue()
+
+	pattern, err := b.mapKeyPattern(kwargs)
 	if err != nil {
 		return starlark.None, err
 	}
@@ -130,7 +188,12 @@ func (b overlayModule) mapKey(
 		oldVal := core.NewStarlarkValue(args.Index(1)).AsGoValue()
 		newVal := core.NewStarlarkValue(args.Index(2)).AsGoValue()
 
-		result, err := b.compareByMapKey(keyName, oldVal, newVal)
+		var result bool
+		if pattern != nil {
+			result, err = b.matchByMapKeyPattern(keyName, pattern, oldVal)
+		} else {
+			result, err = b.compareByMapKey(keyName, oldVal, newVal)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -141,7 +204,81 @@ func (b overlayModule) mapKey(
 	return starlark.NewBuiltin("overlay.map_key_matcher", core.ErrWrapper(matchFunc)), nil
 }
 
-func (b overlayModule) compareByMapKey(keyName string, oldVal, newVal interface{}) (bool, error) {
+// MapKeyTextMatcher returns map item matcher that matches items
+// with key written as given text regardless of key's type
+// (e.g. "200" matches both int key 200 and string key "200")
+func MapKeyTextMatcher(text string) starlark.Value {
+	matchFunc := func(thread *starlark.Thread, f *starlark.Builtin,
+		args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+		if args.Len() != 3 {
+			return starlark.None, fmt.Errorf("expected exactly 3 arguments")
+		}
+
+		key := core.NewStarlarkValue(args.Index(0)).AsGoValue()
+		if key == nil {
+			return starlark.Bool(text == "null"), nil
+		}
+
+		return starlark.Bool(fmt.Sprintf("%v", key) == text), nil
+	}
+
+	return starlark.NewBuiltin("overlay.map_key_text_matcher", core.ErrWrapper(matchFunc))
+}
+
+func (b overlayModule) mapKeyPattern(kwargs []starlark.Tuple) (*regexp.Regexp, error) {
+	var pattern *regexp.Regexp
+
+	for _, kwarg := range kwargs {
+		kwargName, err := core.NewStarlarkValue(kwarg[0]).AsString()
+		if err != nil {
+			return nil, err
+		}
+
+		var compiler PatternCompiler
+
+		switch kwargName {
+		case "regex":
+			compiler = CompileRegexpPattern
+		case "glob":
+			compiler = CompileGlobPattern
+		default:
+			return nil, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+
+		if pattern != nil {
+			return nil, fmt.Errorf("Expected only one of keyword arguments ('regex', 'glob') specified")
+		}
+
+		patternStr, err := core.NewStarlarkValue(kwarg[1]).AsString()
+		if err != nil {
+			return nil, err
+		}
+
+		pattern, err = compiler(patternStr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return pattern, nil
+}
+
+func (b overlayModule) matchByMapKeyPattern(keyName interface{}, pattern *regexp.Regexp, oldVal interface{}) (bool, error) {
+	oldKeyVal, err := b.pullOutMapValue(keyName, oldVal)
+	if err != nil {
+		return false, err
+	}
+
+	typedOldKeyVal, ok := oldKeyVal.(string)
+	if !ok {
+		return false, nil
+	}
+
+	return pattern.MatchString(typedOldKeyVal), nil
+}
+
+func (b overlayModule) compareByMapKey(keyName interface{}, oldVal, newVal interface{}) (bool, error) {
 	oldKeyVal, err := b.pullOutMapValue(keyName, oldVal)
 	if err != nil {
 		return false, err
@@ -156,19 +293,30 @@ func (b overlayModule) compareByMapKey(keyName string, oldVal, newVal interface{
 	return result, nil
 }
 
-func (b overlayModule) pullOutMapValue(keyName string, val interface{}) (interface{}, error) {
+func (b overlayModule) pullOutMapValue(keyName interface{}, val interface{}) (interface{}, error) {
 	typedMap, ok := val.(*yamlmeta.Map)
 	if !ok {
 		return starlark.None, fmt.Errorf("Expected value to be map, but was %T", val)
 	}
 
 	for _, item := range typedMap.Items {
-		if reflect.DeepEqual(item.Key, keyName) {
+		if (Comparison{}).CompareMapKeys(item.Key, keyName) {
 			return item.Value, nil
 		}
 	}
 
-	return starlark.None, fmt.Errorf("Expected to find mapitem with key '%s', but did not", keyName)
+	return starlark.None, fmt.Errorf("Expected to find mapitem with key '%v', but did not", keyName)
+}
+
+// isMapKeyName indicates whether value can be used
+// as a map key name (e.g. in overlay.map_key or by=...)
+func isMapKeyName(val starlark.Value) bool {
+	switch val.(type) {
+	case starlark.String, starlark.Int, starlark.Float, starlark.Bool:
+		return true
+	default:
+		return false
+	}
 }
 
 func (b overlayModule) Subset(
@@ -179,7 +327,46 @@ func (b overlayModule) Subset(
 		return starlark.None, fmt.Errorf("expected exactly one argument")
 	}
 
-	expectedArg := args.Index(0)
+	return b.subsetMatcher("overlay.subset_matcher", args.Index(0), Comparison{})
+}
+
+func (b overlayModule) RegexpSubset(
+	thread *starlark.Thread, f *starlark.Builtin,
+	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	return b.subsetMatcher("overlay.regexp_subset_matcher", args.Index(0),
+		Comparison{LeafPatterns: CompileRegexpPattern})
+}
+
+func (b overlayModule) GlobSubset(
+	thread *starlark.Thread, f *starlark.Builtin,
+	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	return b.subsetMatcher("overlay.glob_subset_matcher", args.Index(0),
+		Comparison{LeafPatterns: CompileGlobPattern})
+}
+
+func (b overlayModule) subsetMatcher(name string, expectedArg starlark.Value,
+	comparison Comparison) (starlark.Value, error) {
+
+	if comparison.LeafPatterns != nil {
+		expectedVal := yamlmeta.NewASTFromInterface(core.NewStarlarkValue(expectedArg).AsGoValue())
+
+		comparison.compiledPatterns = map[string]*regexp.Regexp{}
+
+		err := comparison.LeafPatterns.compilePatterns(expectedVal, comparison.compiledPatterns)
+		if err != nil {
+			return starlark.None, err
+		}
+	}
 
 	matchFunc := func(thread *starlark.Thread, f *starlark.Builtin,
 		args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -201,11 +388,11 @@ func (b overlayModule) Subset(
 			expectedObj = &yamlmeta.Document{Value: expectedObj}
 		}
 
-		result, _ := Comparison{}.Compare(actualObj, expectedObj)
+		result, _ := comparison.Compare(actualObj, expectedObj)
 		return starlark.Bool(result), nil
 	}
 
-	return starlark.NewBuiltin("overlay.subset_matcher", core.ErrWrapper(matchFunc)), nil
+	return starlark.NewBuiltin(name, core.ErrWrapper(matchFunc)), nil
 }
 
 func (b overlayModule) AndOp(
@@ -216,6 +403,10 @@ func (b overlayModule) AndOp(
 		return starlark.None, fmt.Errorf("expected at least one argument")
 	}
 
+	if hasAssertPredicates(andArgs) {
+		return andAssertPredicate(andArgs), nil
+	}
+
 	matchFunc := func(thread *starlark.Thread, f *starlark.Builtin,
 		args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 
@@ -251,6 +442,10 @@ func (b overlayModule) OrOp(
 		return starlark.None, fmt.Errorf("expected at least one argument")
 	}
 
+	if hasAssertPredicates(orArgs) {
+		return orAssertPredicate(orArgs), nil
+	}
+
 	matchFunc := func(thread *starlark.Thread, f *starlark.Builtin,
 		args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 
@@ -0,0 +1,117 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package overlay
+
+import (
+	"fmt"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/ytt/pkg/template"
+	tplcore "github.com/k14s/ytt/pkg/template/core"
+	"github.com/k14s/ytt/pkg/yamlmeta"
+	"github.com/k14s/ytt/pkg/yamltemplate"
+)
+
+const (
+	ApplyIfAnnotationKwargExists string = "exists"
+	ApplyIfAnnotationKwargVia    string = "via"
+)
+
+type ApplyIfAnnotation struct {
+	newDoc *yamlmeta.Document
+	thread *starlark.Thread
+	exists *starlark.Value
+	via    *starlark.Value
+}
+
+func NewApplyIfAnnotation(newDoc *yamlmeta.Document, thread *starlark.Thread) (ApplyIfAnnotation, error) {
+	annotation := ApplyIfAnnotation{
+		newDoc: newDoc,
+		thread: thread,
+	}
+	anns := template.NewAnnotations(newDoc)
+
+	if !anns.Has(AnnotationApplyIf) {
+		return annotation, nil
+	}
+
+	kwargs := anns.Kwargs(AnnotationApplyIf)
+	if len(kwargs) == 0 {
+		return annotation, fmt.Errorf("Expected '%s' annotation to have "+
+			"at least one keyword argument (exists=..., via=...)", AnnotationApplyIf)
+	}
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+		switch kwargName {
+		case ApplyIfAnnotationKwargExists:
+			annotation.exists = &kwarg[1]
+		case ApplyIfAnnotationKwargVia:
+			annotation.via = &kwarg[1]
+		default:
+			return annotation, fmt.Errorf(
+				"Unknown '%s' annotation keyword argument '%s'", AnnotationApplyIf, kwargName)
+		}
+	}
+
+	return annotation, nil
+}
+
+// Check determines whether overlay document should be
+// applied based on the current state of left documents
+func (a ApplyIfAnnotation) Check(leftDocSets []*yamlmeta.DocumentSet) (bool, error) {
+	if a.exists != nil {
+		docAnn := DocumentMatchAnnotation{
+			newDoc:  a.newDoc,
+			thread:  a.thread,
+			matcher: a.exists,
+		}
+
+		idxs, _, err := docAnn.MatchNodes(leftDocSets)
+		if err != nil {
+			return false, err
+		}
+		if len(idxs) == 0 {
+			return false, nil
+		}
+	}
+
+	if a.via != nil {
+		return a.checkVia(leftDocSets)
+	}
+
+	return true, nil
+}
+
+func (a ApplyIfAnnotation) checkVia(leftDocSets []*yamlmeta.DocumentSet) (bool, error) {
+	switch typedVal := (*a.via).(type) {
+	case starlark.Callable:
+		var docVals []starlark.Value
+
+		for _, leftDocSet := range leftDocSets {
+			for _, item := range leftDocSet.Items {
+				docVals = append(docVals, yamltemplate.NewGoValueWithYAML(item.Value).AsStarlarkValue())
+			}
+		}
+
+		viaArgs := starlark.Tuple{starlark.NewList(docVals)}
+
+		result, err := starlark.Call(a.thread, *a.via, viaArgs, []starlark.Tuple{})
+		if err != nil {
+			return false, err
+		}
+
+		resultBool, err := tplcore.NewStarlarkValue(result).AsBool()
+		if err != nil {
+			return false, fmt.Errorf("Expected '%s' annotation keyword argument 'via' "+
+				"to return a boolean: %s", AnnotationApplyIf, err)
+		}
+
+		return resultBool, nil
+
+	default:
+		return false, fmt.Errorf("Expected '%s' annotation keyword argument 'via'"+
+			" to be function, but was %T", AnnotationApplyIf, typedVal)
+	}
+}
@@ -4,6 +4,10 @@
 package overlay
 
 import (
+	"fmt"
+
+	"github.com/k14s/ytt/pkg/structmeta"
+	"github.com/k14s/ytt/pkg/template"
 	"github.com/k14s/ytt/pkg/yamlmeta"
 )
 
@@ -34,12 +38,13 @@ func (o OverlayOp) mergeArrayItem(
 	}
 
 	for _, leftIdx := range leftIdxs {
-		replace, err := o.apply(leftArray.Items[leftIdx].Value, newItem.Value, matchChildDefaults)
+		replace, err := o.withLeftPath(NodePathIndex(leftIdx)).apply(leftArray.Items[leftIdx].Value, newItem.Value, matchChildDefaults)
 		if err != nil {
 			return err
 		}
 		if replace {
 			leftArray.Items[leftIdx].Value = newItem.Value
+			o.record(reportMerged, NodePathIndex(leftIdx))
 		}
 	}
 
@@ -63,6 +68,10 @@ func (o OverlayOp) removeArrayItem(
 		return err
 	}
 
+	for _, leftIdx := range leftIdxs {
+		o.record(reportRemoved, NodePathIndex(leftIdx))
+	}
+
 	for _, leftIdx := range leftIdxs {
 		leftArray.Items[leftIdx] = nil
 	}
@@ -103,14 +112,35 @@ func (o OverlayOp) replaceArrayItem(
 		return err
 	}
 
+	// Collect matched items upfront since copying (moving) into
+	// replacement values may shift positions of left items
+	var leftItems []*yamlmeta.ArrayItem
 	for _, leftIdx := range leftIdxs {
-		newVal, err := replaceAnn.Value(leftArray.Items[leftIdx])
+		leftItems = append(leftItems, leftArray.Items[leftIdx])
+	}
+
+	for _, leftItem := range leftItems {
+		newVal, err := replaceAnn.Value(leftItem)
+		if err != nil {
+			return err
+		}
+
+		item := newItem.DeepCopy()
+		item.SetValue(newVal)
+
+		// Resolve sources while replaced item is still present
+		err = o.copyIntoNewNode(item)
 		if err != nil {
 			return err
 		}
 
-		leftArray.Items[leftIdx] = newItem.DeepCopy()
-		leftArray.Items[leftIdx].SetValue(newVal)
+		leftIdx := arrayItemIndex(leftArray, leftItem)
+		if leftIdx == -1 {
+			return fmt.Errorf("Expected replaced array item to not be moved into its replacement")
+		}
+
+		leftArray.Items[leftIdx] = item
+		o.record(reportReplaced, NodePathIndex(leftIdx))
 	}
 
 	return nil
@@ -138,19 +168,40 @@ func (o OverlayOp) insertArrayItem(
 		return err
 	}
 
+	type insertion struct {
+		leftItem *yamlmeta.ArrayItem
+		newItem  *yamlmeta.ArrayItem
+	}
+
+	// Resolve sources before inserting any items so that
+	// paths (e.g. 'items[0]') refer to original positions
+	var insertions []insertion
+	for _, leftIdx := range leftIdxs {
+		insertions = append(insertions, insertion{leftArray.Items[leftIdx], newItem.DeepCopy()})
+	}
+	for _, ins := range insertions {
+		err := o.copyIntoNewNode(ins.newItem)
+		if err != nil {
+			return err
+		}
+	}
+
 	updatedItems := []*yamlmeta.ArrayItem{}
+	insertedItems := []*yamlmeta.ArrayItem{}
 
-	for i, leftItem := range leftArray.Items {
+	for _, leftItem := range leftArray.Items {
 		matched := false
-		for _, leftIdx := range leftIdxs {
-			if i == leftIdx {
+		for _, ins := range insertions {
+			if ins.leftItem == leftItem {
 				matched = true
 				if insertAnn.IsBefore() {
-					updatedItems = append(updatedItems, newItem.DeepCopy())
+					updatedItems = append(updatedItems, ins.newItem)
+					insertedItems = append(insertedItems, ins.newItem)
 				}
 				updatedItems = append(updatedItems, leftItem)
 				if insertAnn.IsAfter() {
-					updatedItems = append(updatedItems, newItem.DeepCopy())
+					updatedItems = append(updatedItems, ins.newItem)
+					insertedItems = append(insertedItems, ins.newItem)
 				}
 				break
 			}
@@ -160,8 +211,16 @@ func (o OverlayOp) insertArrayItem(
 		}
 	}
 
+	if len(insertedItems) != len(insertions) {
+		return fmt.Errorf("Expected array items matched for insertion to not be moved")
+	}
+
 	leftArray.Items = updatedItems
 
+	for _, item := range insertedItems {
+		o.record(reportInserted, NodePathIndex(arrayItemIndex(leftArray, item)))
+	}
+
 	return nil
 }
 
@@ -169,8 +228,10 @@ func (o OverlayOp) appendArrayItem(
 	leftArray *yamlmeta.Array, newItem *yamlmeta.ArrayItem) error {
 
 	// No need to traverse further
-	leftArray.Items = append(leftArray.Items, newItem.DeepCopy())
-	return nil
+	item := newItem.DeepCopy()
+	leftArray.Items = append(leftArray.Items, item)
+	o.record(reportInserted, NodePathIndex(len(leftArray.Items)-1))
+	return o.copyIntoNewNode(item)
 }
 
 func (o OverlayOp) assertArrayItem(
@@ -206,7 +267,7 @@ func (o OverlayOp) assertArrayItem(
 			return err
 		}
 
-		_, err = o.apply(leftArray.Items[leftIdx].Value, newItem.Value, matchChildDefaults)
+		_, err = o.withLeftPath(NodePathIndex(leftIdx)).apply(leftArray.Items[leftIdx].Value, newItem.Value, matchChildDefaults)
 		if err != nil {
 			return err
 		}
@@ -214,3 +275,64 @@ func (o OverlayOp) assertArrayItem(
 
 	return nil
 }
+
+func (o OverlayOp) copyArrayItem(
+	leftArray *yamlmeta.Array, newItem *yamlmeta.ArrayItem,
+	op structmeta.AnnotationName, parentMatchChildDefaults MatchChildDefaultsAnnotation) error {
+
+	copyAnn, err := NewCopyAnnotation(newItem, op, o.Thread)
+	if err != nil {
+		return err
+	}
+
+	var leftIdxs []int
+
+	// Without explicit match, copied value is appended
+	if template.NewAnnotations(newItem).Has(AnnotationMatch) {
+		ann, err := NewArrayItemMatchAnnotation(newItem, parentMatchChildDefaults, o.Thread)
+		if err != nil {
+			return err
+		}
+
+		leftIdxs, err = ann.Indexes(leftArray)
+		if err != nil {
+			if err, ok := err.(MatchAnnotationNumMatchError); ok && err.isConditional() {
+				return nil
+			}
+			return err
+		}
+	}
+
+	source, err := copyAnn.Source(o)
+	if err != nil {
+		return err
+	}
+
+	if len(leftIdxs) == 0 {
+		item := newItem.DeepCopy()
+		item.SetValue(source.Value())
+		leftArray.Items = append(leftArray.Items, item)
+		o.record(reportInserted, NodePathIndex(len(leftArray.Items)-1))
+	}
+
+	for _, leftIdx := range leftIdxs {
+		leftArray.Items[leftIdx].SetValue(source.Value())
+		o.record(reportReplaced, NodePathIndex(leftIdx))
+	}
+
+	if copyAnn.IsMove() {
+		o.recordPath(reportRemoved, source.ReportPath())
+		return source.Remove()
+	}
+
+	return nil
+}
+
+func arrayItemIndex(array *yamlmeta.Array, item *yamlmeta.ArrayItem) int {
+	for i, currItem := range array.Items {
+		if currItem == item {
+			return i
+		}
+	}
+	return -1
+}
@@ -33,16 +33,19 @@ func NewArrayItemMatchAnnotation(newItem *yamlmeta.ArrayItem,
 	}
 	anns := template.NewAnnotations(newItem)
 
-	if !anns.Has(AnnotationMatch) {
-		return annotation, fmt.Errorf(
-			"Expected array item to have '%s' annotation", AnnotationMatch)
+	if !defaults.mergeStrategy.HasKeys() {
+		if !anns.Has(AnnotationMatch) {
+			return annotation, fmt.Errorf(
+				"Expected array item to have '%s' annotation", AnnotationMatch)
+		}
+
+		if len(anns.Kwargs(AnnotationMatch)) == 0 {
+			return annotation, fmt.Errorf("Expected '%s' annotation to have "+
+				"at least one keyword argument (by=..., expects=...)", AnnotationMatch)
+		}
 	}
 
 	kwargs := anns.Kwargs(AnnotationMatch)
-	if len(kwargs) == 0 {
-		return annotation, fmt.Errorf("Expected '%s' annotation to have "+
-			"at least one keyword argument (by=..., expects=...)", AnnotationMatch)
-	}
 
 	for _, kwarg := range kwargs {
 		kwargName := string(kwarg[0].(starlark.String))
@@ -63,6 +66,21 @@ func NewArrayItemMatchAnnotation(newItem *yamlmeta.ArrayItem,
 
 	annotation.expects.FillInDefaults(defaults)
 
+	if annotation.matcher == nil && defaults.mergeStrategy.HasKeys() {
+		matcher, err := defaults.mergeStrategy.Matcher(newItem)
+		if err != nil {
+			return annotation, err
+		}
+
+		annotation.matcher = matcher
+
+		// Items without a match are added (same as with strategic merge patch)
+		if annotation.expects.IsEmpty() {
+			missingOK := starlark.Value(starlark.Bool(true))
+			annotation.expects.missingOK = &missingOK
+		}
+	}
+
 	return annotation, nil
 }
 
@@ -83,7 +101,7 @@ func (a ArrayItemMatchAnnotation) MatchNodes(leftArray *yamlmeta.Array) ([]int,
 			"keyword argument 'by' to be specified", AnnotationMatch)
 	}
 
-	if _, ok := (*matcher).(starlark.String); ok {
+	if isMapKeyName(*matcher) {
 		matcherFunc, err := starlark.Call(a.thread, overlayModule{}.MapKey(),
 			starlark.Tuple{*matcher}, []starlark.Tuple{})
 		if err != nil {
@@ -125,6 +143,6 @@ func (a ArrayItemMatchAnnotation) MatchNodes(leftArray *yamlmeta.Array) ([]int,
 
 	default:
 		return nil, nil, fmt.Errorf("Expected '%s' annotation keyword argument 'by' "+
-			"to be either string (for map key) or function, but was %T", AnnotationMatch, typedVal)
+			"to be either map key (string, int, float or bool) or function, but was %T", AnnotationMatch, typedVal)
 	}
 }
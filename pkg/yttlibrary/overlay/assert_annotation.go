@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/ytt/pkg/filepos"
 	"github.com/k14s/ytt/pkg/template"
 	tplcore "github.com/k14s/ytt/pkg/template/core"
 	"github.com/k14s/ytt/pkg/yamlmeta"
@@ -41,6 +42,11 @@ func NewAssertAnnotation(newNode template.EvaluationNode, thread *starlark.Threa
 }
 
 func (a AssertAnnotation) Check(existingNode template.EvaluationNode) error {
+	existingPos := filepos.NewUnknownPosition()
+	if typedNode, ok := existingNode.(yamlmeta.Node); ok {
+		existingPos = typedNode.GetPosition()
+	}
+
 	// Make sure original nodes are not affected in any way
 	existingNode = existingNode.DeepCopyAsInterface().(template.EvaluationNode)
 	newNode := a.newNode.DeepCopyAsInterface().(template.EvaluationNode)
@@ -61,53 +67,73 @@ func (a AssertAnnotation) Check(existingNode template.EvaluationNode) error {
 		return nil
 	}
 
+	viaArgs := starlark.Tuple{
+		yamltemplate.NewGoValueWithYAML(existingVal).AsStarlarkValue(),
+		yamltemplate.NewGoValueWithYAML(newVal).AsStarlarkValue(),
+	}
+
 	switch typedVal := (*a.via).(type) {
-	case starlark.Callable:
-		viaArgs := starlark.Tuple{
-			yamltemplate.NewGoValueWithYAML(existingVal).AsStarlarkValue(),
-			yamltemplate.NewGoValueWithYAML(newVal).AsStarlarkValue(),
+	case *AssertPredicate:
+		ok, msg, err := typedVal.Check(a.thread, viaArgs[0], viaArgs[1])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("Asserted node on %s: %s", existingPos.AsString(), msg)
 		}
+		return nil
 
+	case starlark.Callable:
 		result, err := starlark.Call(a.thread, *a.via, viaArgs, []starlark.Tuple{})
 		if err != nil {
 			return err
 		}
 
-		switch typedResult := result.(type) {
-		case nil, starlark.NoneType:
-			// Assume if via didnt error then it's successful
-			return nil
-
-		case starlark.Bool:
-			if !bool(typedResult) {
-				return fmt.Errorf("Expected via invocation to return true, but was false")
-			}
-			return nil
-
-		default:
-			result := tplcore.NewStarlarkValue(result).AsGoValue()
-
-			// Extract result tuple(bool, string) to determine success
-			if typedResult, ok := result.([]interface{}); ok {
-				if len(typedResult) == 2 {
-					resultSuccess, ok1 := typedResult[0].(bool)
-					resultMsg, ok2 := typedResult[1].(string)
-					if ok1 && ok2 {
-						if !resultSuccess {
-							return fmt.Errorf("Expected via invocation to return true, "+
-								"but was false with message: %s", resultMsg)
-						}
-						return nil
-					}
-				}
+		ok, msg, err := assertViaResult(result)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if len(msg) > 0 {
+				return fmt.Errorf("Expected via invocation to return true, "+
+					"but was false with message: %s", msg)
 			}
-
-			return fmt.Errorf("Expected via invocation to return NoneType, " +
-				"Bool or Tuple(Bool,String), but returned neither of those")
+			return fmt.Errorf("Expected via invocation to return true, but was false")
 		}
+		return nil
 
 	default:
 		return fmt.Errorf("Expected '%s' annotation keyword argument 'via'"+
 			" to be function, but was %T", AnnotationAssert, typedVal)
 	}
 }
+
+// assertViaResult interprets result of via function:
+// NoneType and true indicate success, as well as tuple (true, message)
+func assertViaResult(result starlark.Value) (bool, string, error) {
+	switch typedResult := result.(type) {
+	case nil, starlark.NoneType:
+		// Assume if via didnt error then it's successful
+		return true, "", nil
+
+	case starlark.Bool:
+		return bool(typedResult), "", nil
+
+	default:
+		result := tplcore.NewStarlarkValue(result).AsGoValue()
+
+		// Extract result tuple(bool, string) to determine success
+		if typedResult, ok := result.([]interface{}); ok {
+			if len(typedResult) == 2 {
+				resultSuccess, ok1 := typedResult[0].(bool)
+				resultMsg, ok2 := typedResult[1].(string)
+				if ok1 && ok2 {
+					return resultSuccess, resultMsg, nil
+				}
+			}
+		}
+
+		return false, "", fmt.Errorf("Expected via invocation to return NoneType, " +
+			"Bool or Tuple(Bool,String), but returned neither of those")
+	}
+}
@@ -0,0 +1,321 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/k14s/starlark-go/syntax"
+	"github.com/k14s/ytt/pkg/template/core"
+	"github.com/k14s/ytt/pkg/yamlmeta"
+	"github.com/k14s/ytt/pkg/yamltemplate"
+)
+
+// AssertPredicate is a function usable with @overlay/assert via=...
+// that checks left value and describes expected value on failure.
+// Predicates are returned by overlay.assert_that.* functions.
+type AssertPredicate struct {
+	name  string
+	check func(thread *starlark.Thread, left, right starlark.Value) (bool, string, error)
+}
+
+var _ starlark.Callable = &AssertPredicate{}
+
+func (p *AssertPredicate) Name() string         { return p.name }
+func (p *AssertPredicate) String() string       { return "<" + p.name + ">" }
+func (p *AssertPredicate) Type() string         { return "assert_predicate" }
+func (p *AssertPredicate) Freeze()              {}
+func (p *AssertPredicate) Truth() starlark.Bool { return true }
+func (p *AssertPredicate) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: %s", p.Type())
+}
+
+// CallInternal allows predicate to be called as a regular via function;
+// it returns true or tuple (false, message)
+func (p *AssertPredicate) CallInternal(thread *starlark.Thread,
+	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+	if args.Len() != 2 {
+		return starlark.None, fmt.Errorf("%s: expected exactly 2 arguments", p.name)
+	}
+
+	ok, msg, err := p.Check(thread, args.Index(0), args.Index(1))
+	if err != nil {
+		return starlark.None, fmt.Errorf("%s: %s", p.name, err)
+	}
+	if !ok {
+		return starlark.Tuple{starlark.Bool(false), starlark.String(msg)}, nil
+	}
+	return starlark.Bool(true), nil
+}
+
+func (p *AssertPredicate) Check(thread *starlark.Thread, left, right starlark.Value) (bool, string, error) {
+	return p.check(thread, left, right)
+}
+
+func (b overlayModule) AssertThat() starlark.Value {
+	return &starlarkstruct.Module{
+		Name: "assert_that",
+		Members: starlark.StringDict{
+			"is_type":   starlark.NewBuiltin("overlay.assert_that.is_type", core.ErrWrapper(b.AssertThatIsType)),
+			"in_range":  starlark.NewBuiltin("overlay.assert_that.in_range", core.ErrWrapper(b.AssertThatInRange)),
+			"matches":   starlark.NewBuiltin("overlay.assert_that.matches", core.ErrWrapper(b.AssertThatMatches)),
+			"not_empty": starlark.NewBuiltin("overlay.assert_that.not_empty", core.ErrWrapper(b.AssertThatNotEmpty)),
+			"one_of":    starlark.NewBuiltin("overlay.assert_that.one_of", core.ErrWrapper(b.AssertThatOneOf)),
+		},
+	}
+}
+
+func (b overlayModule) AssertThatIsType(
+	thread *starlark.Thread, f *starlark.Builtin,
+	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	typeName, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	check := func(thread *starlark.Thread, left, right starlark.Value) (bool, string, error) {
+		leftTypeName := assertTypeName(left)
+		if leftTypeName == typeName {
+			return true, "", nil
+		}
+		return false, fmt.Sprintf("Expected value to be of type '%s', but was '%s'", typeName, leftTypeName), nil
+	}
+
+	return &AssertPredicate{name: "overlay.assert_that.is_type", check: check}, nil
+}
+
+func (b overlayModule) AssertThatInRange(
+	thread *starlark.Thread, f *starlark.Builtin,
+	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+	if args.Len() != 2 {
+		return starlark.None, fmt.Errorf("expected exactly 2 arguments")
+	}
+
+	min, max := args.Index(0), args.Index(1)
+
+	for _, val := range []starlark.Value{min, max} {
+		if !isNumber(val) {
+			return starlark.None, fmt.Errorf("expected range bounds to be numbers, but was %s", val.Type())
+		}
+	}
+
+	check := func(thread *starlark.Thread, left, right starlark.Value) (bool, string, error) {
+		expected := fmt.Sprintf("Expected value to be in range [%s, %s]", min.String(), max.String())
+
+		if !isNumber(left) {
+			return false, fmt.Sprintf("%s, but was %s", expected, left.Type()), nil
+		}
+
+		aboveMin, err := starlark.Compare(syntax.GE, left, min)
+		if err != nil {
+			return false, "", err
+		}
+		belowMax, err := starlark.Compare(syntax.LE, left, max)
+		if err != nil {
+			return false, "", err
+		}
+		if aboveMin && belowMax {
+			return true, "", nil
+		}
+		return false, fmt.Sprintf("%s, but was %s", expected, assertValueDesc(left)), nil
+	}
+
+	return &AssertPredicate{name: "overlay.assert_that.in_range", check: check}, nil
+}
+
+func (b overlayModule) AssertThatMatches(
+	thread *starlark.Thread, f *starlark.Builtin,
+	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	pattern, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	re, err := CompileRegexpPattern(pattern)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	check := func(thread *starlark.Thread, left, right starlark.Value) (bool, string, error) {
+		expected := fmt.Sprintf("Expected value to match regular expression '%s'", re.String())
+
+		typedLeft, ok := left.(starlark.String)
+		if !ok {
+			return false, fmt.Sprintf("%s, but was %s", expected, left.Type()), nil
+		}
+		if re.MatchString(string(typedLeft)) {
+			return true, "", nil
+		}
+		return false, fmt.Sprintf("%s, but was %s", expected, assertValueDesc(left)), nil
+	}
+
+	return &AssertPredicate{name: "overlay.assert_that.matches", check: check}, nil
+}
+
+func (b overlayModule) AssertThatNotEmpty(
+	thread *starlark.Thread, f *starlark.Builtin,
+	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+	if args.Len() != 0 {
+		return starlark.None, fmt.Errorf("expected no arguments")
+	}
+
+	check := func(thread *starlark.Thread, left, right starlark.Value) (bool, string, error) {
+		switch {
+		case left == starlark.None:
+			// null is considered empty
+		case starlark.Len(left) > 0:
+			return true, "", nil
+		case starlark.Len(left) < 0:
+			return false, fmt.Sprintf("Expected value to not be empty, but was %s "+
+				"(only strings, lists and maps can be checked)", left.Type()), nil
+		}
+		return false, fmt.Sprintf("Expected value to not be empty, but was %s", assertValueDesc(left)), nil
+	}
+
+	return &AssertPredicate{name: "overlay.assert_that.not_empty", check: check}, nil
+}
+
+func (b overlayModule) AssertThatOneOf(
+	thread *starlark.Thread, f *starlark.Builtin,
+	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+	if args.Len() == 0 {
+		return starlark.None, fmt.Errorf("expected at least one argument")
+	}
+
+	allowedVals := append(starlark.Tuple{}, args...)
+
+	check := func(thread *starlark.Thread, left, right starlark.Value) (bool, string, error) {
+		for _, val := range allowedVals {
+			// Values of different types are never equal
+			if val.Type() != left.Type() {
+				continue
+			}
+			equal, err := starlark.Equal(left, val)
+			if err != nil {
+				return false, "", err
+			}
+			if equal {
+				return true, "", nil
+			}
+		}
+
+		var allowedDescs []string
+		for _, val := range allowedVals {
+			allowedDescs = append(allowedDescs, val.String())
+		}
+
+		return false, fmt.Sprintf("Expected value to be one of [%s], but was %s",
+			strings.Join(allowedDescs, ", "), assertValueDesc(left)), nil
+	}
+
+	return &AssertPredicate{name: "overlay.assert_that.one_of", check: check}, nil
+}
+
+// andAssertPredicate combines predicates (or regular via functions)
+// so that all of them must succeed; first failure is reported
+func andAssertPredicate(vals starlark.Tuple) *AssertPredicate {
+	check := func(thread *starlark.Thread, left, right starlark.Value) (bool, string, error) {
+		for _, val := range vals {
+			ok, msg, err := checkAssertVia(thread, val, left, right)
+			if err != nil || !ok {
+				return ok, msg, err
+			}
+		}
+		return true, "", nil
+	}
+	return &AssertPredicate{name: "overlay.and_op", check: check}
+}
+
+// orAssertPredicate combines predicates (or regular via functions)
+// so that at least one of them must succeed; all failures are reported
+func orAssertPredicate(vals starlark.Tuple) *AssertPredicate {
+	check := func(thread *starlark.Thread, left, right starlark.Value) (bool, string, error) {
+		var msgs []string
+		for _, val := range vals {
+			ok, msg, err := checkAssertVia(thread, val, left, right)
+			if err != nil || ok {
+				return ok, msg, err
+			}
+			msgs = append(msgs, msg)
+		}
+		return false, strings.Join(msgs, "; or "), nil
+	}
+	return &AssertPredicate{name: "overlay.or_op", check: check}
+}
+
+func checkAssertVia(thread *starlark.Thread, via, left, right starlark.Value) (bool, string, error) {
+	if typedVia, ok := via.(*AssertPredicate); ok {
+		return typedVia.Check(thread, left, right)
+	}
+
+	result, err := starlark.Call(thread, via, starlark.Tuple{left, right}, []starlark.Tuple{})
+	if err != nil {
+		return false, "", err
+	}
+
+	ok, msg, err := assertViaResult(result)
+	if err == nil && !ok && len(msg) == 0 {
+		msg = "Expected via invocation to return true, but was false"
+	}
+	return ok, msg, err
+}
+
+func hasAssertPredicates(vals starlark.Tuple) bool {
+	for _, val := range vals {
+		if _, ok := val.(*AssertPredicate); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// assertTypeName returns Starlark type name of a value;
+// YAML maps and arrays are named as their Starlark counterparts
+func assertTypeName(val starlark.Value) string {
+	if typedVal, ok := val.(*yamltemplate.StarlarkFragment); ok {
+		switch typedVal.AsGoValue().(type) {
+		case *yamlmeta.Map:
+			return "dict"
+		case *yamlmeta.Array:
+			return "list"
+		}
+	}
+	return val.Type()
+}
+
+// assertValueDesc describes value in Starlark syntax
+// (YAML fragments are described via their plain values)
+func assertValueDesc(val starlark.Value) string {
+	if typedVal, ok := val.(*yamltemplate.StarlarkFragment); ok {
+		goVal := yamlmeta.NewGoFromAST(typedVal.AsGoValue())
+		return core.NewGoValue(goVal).AsStarlarkValue().String()
+	}
+	return val.String()
+}
+
+func isNumber(val starlark.Value) bool {
+	switch val.(type) {
+	case starlark.Int, starlark.Float:
+		return true
+	default:
+		return false
+	}
+}
@@ -6,11 +6,20 @@ package overlay
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 
 	"github.com/k14s/ytt/pkg/yamlmeta"
 )
 
-type Comparison struct{}
+type Comparison struct {
+	// When set, string leafs on the right are treated
+	// as patterns that left leafs are expected to match
+	LeafPatterns PatternCompiler
+
+	// compiledPatterns caches patterns compiled via LeafPatterns
+	// so that they are not recompiled for every compared leaf
+	compiledPatterns map[string]*regexp.Regexp
+}
 
 func (b Comparison) Compare(left, right interface{}) (bool, string) {
 	switch typedRight := right.(type) {
@@ -34,7 +43,7 @@ func (b Comparison) Compare(left, right interface{}) (bool, string) {
 		for _, rightItem := range typedRight.Items {
 			matched := false
 			for _, leftItem := range typedLeft.Items {
-				if reflect.DeepEqual(leftItem.Key, rightItem.Key) {
+				if b.CompareMapKeys(leftItem.Key, rightItem.Key) {
 					result, explain := b.Compare(leftItem, rightItem)
 					if !result {
 						return false, explain
@@ -89,6 +98,12 @@ func (b Comparison) Compare(left, right interface{}) (bool, string) {
 }
 
 func (b Comparison) CompareLeafs(left, right interface{}) (bool, string) {
+	if b.LeafPatterns != nil {
+		if typedRight, ok := right.(string); ok {
+			return b.compareAsPattern(left, typedRight)
+		}
+	}
+
 	if reflect.DeepEqual(left, right) {
 		return true, ""
 	}
@@ -100,6 +115,39 @@ func (b Comparison) CompareLeafs(left, right interface{}) (bool, string) {
 	return false, fmt.Sprintf("Expected leaf values to match %T %T", left, right)
 }
 
+func (b Comparison) compareAsPattern(left interface{}, pattern string) (bool, string) {
+	typedLeft, ok := left.(string)
+	if !ok {
+		return false, fmt.Sprintf("Expected leaf value to be string to match pattern '%s', but was %T", pattern, left)
+	}
+
+	re, found := b.compiledPatterns[pattern]
+	if !found {
+		var err error
+		re, err = b.LeafPatterns(pattern)
+		if err != nil {
+			return false, err.Error()
+		}
+	}
+
+	if !re.MatchString(typedLeft) {
+		return false, fmt.Sprintf("Expected leaf value '%s' to match pattern '%s'", typedLeft, pattern)
+	}
+
+	return true, ""
+}
+
+// CompareMapKeys compares map keys taking into account that
+// integer keys may be represented by different Go types
+// (e.g. int when parsed from YAML vs int64 when produced by Starlark)
+func (b Comparison) CompareMapKeys(left, right interface{}) bool {
+	if reflect.DeepEqual(left, right) {
+		return true
+	}
+	result, _ := b.compareAsInt64s(left, right)
+	return result
+}
+
 func (b Comparison) compareAsInt64s(left, right interface{}) (bool, string) {
 	leftVal, ok := b.upcastToInt64(left)
 	if !ok {
@@ -0,0 +1,318 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package overlay
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/ytt/pkg/structmeta"
+	"github.com/k14s/ytt/pkg/template"
+	tplcore "github.com/k14s/ytt/pkg/template/core"
+	"github.com/k14s/ytt/pkg/yamlmeta"
+)
+
+const (
+	CopyAnnotationKwargFromPath string = "from_path"
+	CopyAnnotationKwargFromDoc  string = "from_doc"
+)
+
+// CopyAnnotation describes @overlay/copy and @overlay/move
+// operations that take value from another location on the left
+type CopyAnnotation struct {
+	newNode template.EvaluationNode
+	name    structmeta.AnnotationName
+	thread  *starlark.Thread
+
+	from    NodePath
+	fromDoc *starlark.Value
+}
+
+type CopySource struct {
+	path   NodePath
+	parent yamlmeta.Node
+	item   yamlmeta.Node
+
+	// rootPath is a path of the node that source
+	// was found within (used for reports)
+	rootPath NodePath
+}
+
+func NewCopyAnnotation(newNode template.EvaluationNode,
+	name structmeta.AnnotationName, thread *starlark.Thread) (CopyAnnotation, error) {
+
+	annotation := CopyAnnotation{
+		newNode: newNode,
+		name:    name,
+		thread:  thread,
+	}
+	kwargs := template.NewAnnotations(newNode).Kwargs(name)
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+		switch kwargName {
+		case CopyAnnotationKwargFromPath:
+			path, err := NewNodePath(kwarg[1])
+			if err != nil {
+				return annotation, fmt.Errorf("Expected '%s' annotation keyword argument '%s' "+
+					"to be a path: %s", name, kwargName, err)
+			}
+			annotation.from = path
+		case CopyAnnotationKwargFromDoc:
+			annotation.fromDoc = &kwarg[1]
+		default:
+			return annotation, fmt.Errorf(
+				"Unknown '%s' annotation keyword argument '%s'", name, kwargName)
+		}
+	}
+
+	if len(annotation.from) == 0 {
+		return annotation, fmt.Errorf("Expected '%s' annotation "+
+			"keyword argument '%s' to be specified", name, CopyAnnotationKwargFromPath)
+	}
+
+	return annotation, nil
+}
+
+func (a CopyAnnotation) IsMove() bool { return a.name == AnnotationMove }
+
+// Source finds node referenced by 'from' path within either
+// currently overlaid left node or document selected via 'from_doc'
+func (a CopyAnnotation) Source(o OverlayOp) (CopySource, error) {
+	root := o.leftRoot
+	rootPath := o.leftRootPath
+
+	if a.fromDoc != nil {
+		if o.leftDocSets == nil {
+			return CopySource{}, fmt.Errorf("Expected '%s' annotation keyword argument '%s' "+
+				"to be used only when overlaying documents", a.name, CopyAnnotationKwargFromDoc)
+		}
+
+		docAnn := DocumentMatchAnnotation{
+			newDoc:  &yamlmeta.Document{Value: a.newNode.GetValues()[0]},
+			thread:  a.thread,
+			matcher: a.fromDoc,
+			expects: MatchAnnotationExpectsKwarg{thread: a.thread},
+		}
+
+		idxs, err := docAnn.IndexTuples(o.leftDocSets)
+		if err != nil {
+			return CopySource{}, fmt.Errorf("Finding source document via '%s': %s", CopyAnnotationKwargFromDoc, err)
+		}
+
+		root = o.leftDocSets[idxs[0][0]].Items[idxs[0][1]]
+		rootPath = NodePath{docPathIndex(o.leftDocSets, idxs[0])}
+	}
+
+	if root == nil {
+		return CopySource{}, fmt.Errorf("Expected '%s' annotation keyword argument '%s' "+
+			"to be specified to resolve path '%s' within new document", a.name, CopyAnnotationKwargFromDoc, a.from)
+	}
+
+	source, err := a.from.Find(root)
+	source.rootPath = rootPath
+	return source, err
+}
+
+func (s CopySource) Value() interface{} {
+	val := s.item.GetValues()[0]
+	if typedVal, ok := val.(yamlmeta.Node); ok {
+		return typedVal.DeepCopyAsInterface()
+	}
+	return val
+}
+
+// ReportPath returns path of the source relative to the left value
+func (s CopySource) ReportPath() NodePath {
+	return append(append(NodePath{}, s.rootPath...), s.path...)
+}
+
+// Remove deletes source node from its parent. Node is found
+// by identity since its index may have changed after copying.
+func (s CopySource) Remove() error {
+	switch typedParent := s.parent.(type) {
+	case *yamlmeta.Map:
+		for i, item := range typedParent.Items {
+			if item == s.item {
+				typedParent.Items = append(typedParent.Items[:i], typedParent.Items[i+1:]...)
+				return nil
+			}
+		}
+	case *yamlmeta.Array:
+		for i, item := range typedParent.Items {
+			if item == s.item {
+				typedParent.Items = append(typedParent.Items[:i], typedParent.Items[i+1:]...)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("Expected to find source node at path '%s' to remove it, but did not", s.path)
+}
+
+// NodePath is a list of map keys and array indexes. Int pieces
+// (e.g. from ["codes", 200]) are resolved as either array indexes
+// or map keys depending on the node; NodePathIndex is always an index.
+type NodePath []interface{}
+
+// NodePathIndex is an array index within NodePath (e.g. from 'ports[0]')
+type NodePathIndex int
+
+// NewNodePath builds path from either a string in the form of
+// 'key1.key2[0].key3' or a list of map keys and array indexes
+func NewNodePath(val starlark.Value) (NodePath, error) {
+	switch typedVal := val.(type) {
+	case starlark.String:
+		return ParseNodePath(string(typedVal))
+
+	case *starlark.List, starlark.Tuple:
+		var result NodePath
+		for _, piece := range tplcore.NewStarlarkValue(val).AsGoValue().([]interface{}) {
+			switch typedPiece := piece.(type) {
+			case string:
+				result = append(result, typedPiece)
+			case int64:
+				result = append(result, int(typedPiece))
+			default:
+				return nil, fmt.Errorf("Expected path piece to be either string or int, but was %T", piece)
+			}
+		}
+		return result, nil
+
+	default:
+		return nil, fmt.Errorf("Expected string or list, but was %s", val.Type())
+	}
+}
+
+func ParseNodePath(str string) (NodePath, error) {
+	var result NodePath
+
+	for _, segment := range strings.Split(str, ".") {
+		key := segment
+		var idxs []int
+
+		for strings.HasSuffix(key, "]") {
+			openIdx := strings.LastIndex(key, "[")
+			if openIdx == -1 {
+				return nil, fmt.Errorf("Expected segment '%s' to have matching '['", segment)
+			}
+			idx, err := strconv.Atoi(key[openIdx+1 : len(key)-1])
+			if err != nil {
+				return nil, fmt.Errorf("Expected array index in segment '%s' to be an integer", segment)
+			}
+			idxs = append([]int{idx}, idxs...)
+			key = key[:openIdx]
+		}
+
+		if len(key) > 0 {
+			result = append(result, key)
+		} else if len(idxs) == 0 {
+			return nil, fmt.Errorf("Expected path '%s' to not have empty segments", str)
+		}
+
+		for _, idx := range idxs {
+			result = append(result, NodePathIndex(idx))
+		}
+	}
+
+	return result, nil
+}
+
+// String returns path in the form of 'key1.key2[0].key3';
+// non-string map keys are wrapped in parentheses (e.g. 'codes.(200)')
+func (p NodePath) String() string {
+	var result string
+	for _, piece := range p {
+		if typedPiece, ok := piece.(NodePathIndex); ok {
+			result += fmt.Sprintf("[%d]", typedPiece)
+			continue
+		}
+		if len(result) > 0 {
+			result += "."
+		}
+		if _, ok := piece.(string); ok {
+			result += fmt.Sprintf("%v", piece)
+		} else {
+			result += fmt.Sprintf("(%v)", piece)
+		}
+	}
+	return result
+}
+
+// Append returns new path with given piece at the end
+func (p NodePath) Append(piece interface{}) NodePath {
+	return append(append(NodePath{}, p...), piece)
+}
+
+// Find resolves path pieces against actual nodes: int pieces are
+// array indexes within arrays and keys within maps (e.g. 'codes: {200: OK}').
+// Path of returned source is made of resolved pieces.
+func (p NodePath) Find(root interface{}) (CopySource, error) {
+	source := CopySource{}
+	curr := root
+
+	if typedDoc, ok := curr.(*yamlmeta.Document); ok {
+		curr = typedDoc.Value
+	}
+
+	for _, piece := range p {
+		switch typedCurr := curr.(type) {
+		case *yamlmeta.Map:
+			if _, ok := piece.(NodePathIndex); ok {
+				return source, fmt.Errorf("Expected value at path '%s' to be array, but was %T", source.path, curr)
+			}
+
+			var found []*yamlmeta.MapItem
+			for _, item := range typedCurr.Items {
+				if (Comparison{}).CompareMapKeys(item.Key, piece) {
+					found = append(found, item)
+				}
+			}
+
+			switch len(found) {
+			case 0:
+				return source, fmt.Errorf("Expected to find source at path '%s', but did not", source.path.Append(piece))
+			case 1:
+				source.path = source.path.Append(found[0].Key)
+				source.parent = typedCurr
+				source.item = found[0]
+				curr = found[0].Value
+			default:
+				return source, fmt.Errorf("Expected to find exactly one source at path '%s', but found %d", source.path.Append(piece), len(found))
+			}
+
+		case *yamlmeta.Array:
+			var idx int
+
+			switch typedPiece := piece.(type) {
+			case NodePathIndex:
+				idx = int(typedPiece)
+			case int:
+				idx = typedPiece
+			default:
+				return source, fmt.Errorf("Expected value at path '%s' to be map, but was %T", source.path, curr)
+			}
+
+			origIdx := idx
+			if idx < 0 {
+				idx += len(typedCurr.Items)
+			}
+			if idx < 0 || idx >= len(typedCurr.Items) {
+				return source, fmt.Errorf("Expected to find source at path '%s', but array has %d items",
+					source.path.Append(NodePathIndex(origIdx)), len(typedCurr.Items))
+			}
+
+			source.path = source.path.Append(NodePathIndex(idx))
+			source.parent = typedCurr
+			source.item = typedCurr.Items[idx]
+			curr = typedCurr.Items[idx].Value
+
+		default:
+			return source, fmt.Errorf("Expected value at path '%s' to be map or array, but was %T", source.path, curr)
+		}
+	}
+
+	return source, nil
+}
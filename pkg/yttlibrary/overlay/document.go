@@ -30,12 +30,15 @@ func (o OverlayOp) mergeDocument(
 	}
 
 	for _, leftIdx := range leftIdxs {
-		replace, err := o.apply(leftDocSets[leftIdx[0]].Items[leftIdx[1]].Value, newDoc.Value, matchChildDefaults)
+		leftDoc := leftDocSets[leftIdx[0]].Items[leftIdx[1]]
+
+		replace, err := o.withLeftRoot(leftDoc, NodePath{docPathIndex(leftDocSets, leftIdx)}).apply(leftDoc.Value, newDoc.Value, matchChildDefaults)
 		if err != nil {
 			return err
 		}
 		if replace {
 			leftDocSets[leftIdx[0]].Items[leftIdx[1]].Value = newDoc.Value
+			o.record(reportMerged, docPathIndex(leftDocSets, leftIdx))
 		}
 	}
 
@@ -59,6 +62,10 @@ func (o OverlayOp) removeDocument(
 		return err
 	}
 
+	for _, leftIdx := range leftIdxs {
+		o.record(reportRemoved, docPathIndex(leftDocSets, leftIdx))
+	}
+
 	for _, leftIdx := range leftIdxs {
 		leftDocSets[leftIdx[0]].Items[leftIdx[1]] = nil
 	}
@@ -102,13 +109,24 @@ func (o OverlayOp) replaceDocument(
 	}
 
 	for _, leftIdx := range leftIdxs {
-		newVal, err := replaceAnn.Value(leftDocSets[leftIdx[0]].Items[leftIdx[1]])
+		leftDoc := leftDocSets[leftIdx[0]].Items[leftIdx[1]]
+
+		newVal, err := replaceAnn.Value(leftDoc)
 		if err != nil {
 			return err
 		}
 
-		leftDocSets[leftIdx[0]].Items[leftIdx[1]] = newDoc.DeepCopy()
-		leftDocSets[leftIdx[0]].Items[leftIdx[1]].SetValue(newVal)
+		doc := newDoc.DeepCopy()
+		doc.SetValue(newVal)
+
+		// Resolve sources relative to replaced document
+		err = o.withLeftRoot(leftDoc, NodePath{docPathIndex(leftDocSets, leftIdx)}).copyIntoNewNode(doc)
+		if err != nil {
+			return err
+		}
+
+		leftDocSets[leftIdx[0]].Items[leftIdx[1]] = doc
+		o.record(reportReplaced, docPathIndex(leftDocSets, leftIdx))
 	}
 
 	return nil
@@ -136,20 +154,36 @@ func (o OverlayOp) insertDocument(
 		return err
 	}
 
+	// Resolve sources before inserting any documents
+	// so that new documents are not matched by from_doc
+	var newDocs []*yamlmeta.Document
+	for range leftIdxs {
+		doc := newDoc.DeepCopy()
+		err := o.withLeftRoot(nil, nil).copyIntoNewNode(doc)
+		if err != nil {
+			return err
+		}
+		newDocs = append(newDocs, doc)
+	}
+
+	var insertedIdxs [][]int
+
 	for i, leftDocSet := range leftDocSets {
 		updatedDocs := []*yamlmeta.Document{}
 
 		for j, leftItem := range leftDocSet.Items {
 			matched := false
-			for _, leftIdx := range leftIdxs {
+			for k, leftIdx := range leftIdxs {
 				if leftIdx[0] == i && leftIdx[1] == j {
 					matched = true
 					if insertAnn.IsBefore() {
-						updatedDocs = append(updatedDocs, newDoc.DeepCopy())
+						updatedDocs = append(updatedDocs, newDocs[k])
+						insertedIdxs = append(insertedIdxs, []int{i, len(updatedDocs) - 1})
 					}
 					updatedDocs = append(updatedDocs, leftItem)
 					if insertAnn.IsAfter() {
-						updatedDocs = append(updatedDocs, newDoc.DeepCopy())
+						updatedDocs = append(updatedDocs, newDocs[k])
+						insertedIdxs = append(insertedIdxs, []int{i, len(updatedDocs) - 1})
 					}
 					break
 				}
@@ -162,6 +196,10 @@ func (o OverlayOp) insertDocument(
 		leftDocSet.Items = updatedDocs
 	}
 
+	for _, insertedIdx := range insertedIdxs {
+		o.record(reportInserted, docPathIndex(leftDocSets, insertedIdx))
+	}
+
 	return nil
 }
 
@@ -169,7 +207,16 @@ func (o OverlayOp) appendDocument(
 	leftDocSets []*yamlmeta.DocumentSet, newDoc *yamlmeta.Document) error {
 
 	// No need to traverse further
-	leftDocSets[len(leftDocSets)-1].Items = append(leftDocSets[len(leftDocSets)-1].Items, newDoc.DeepCopy())
+	doc := newDoc.DeepCopy()
+
+	err := o.withLeftRoot(nil, nil).copyIntoNewNode(doc)
+	if err != nil {
+		return err
+	}
+
+	lastDocSet := leftDocSets[len(leftDocSets)-1]
+	lastDocSet.Items = append(lastDocSet.Items, doc)
+	o.record(reportInserted, docPathIndex(leftDocSets, []int{len(leftDocSets) - 1, len(lastDocSet.Items) - 1}))
 	return nil
 }
 
@@ -206,7 +253,9 @@ func (o OverlayOp) assertDocument(
 			return err
 		}
 
-		_, err = o.apply(leftDocSets[leftIdx[0]].Items[leftIdx[1]].Value, newDoc.Value, matchChildDefaults)
+		leftDoc := leftDocSets[leftIdx[0]].Items[leftIdx[1]]
+
+		_, err = o.withLeftRoot(leftDoc, NodePath{docPathIndex(leftDocSets, leftIdx)}).apply(leftDoc.Value, newDoc.Value, matchChildDefaults)
 		if err != nil {
 			return err
 		}
@@ -214,3 +263,20 @@ func (o OverlayOp) assertDocument(
 
 	return nil
 }
+
+// docPathIndex returns index of the document across all document sets
+// as used in report paths (removed documents are not counted)
+func docPathIndex(leftDocSets []*yamlmeta.DocumentSet, leftIdx []int) NodePathIndex {
+	var result int
+	for i, docSet := range leftDocSets {
+		for j, doc := range docSet.Items {
+			if i == leftIdx[0] && j == leftIdx[1] {
+				return NodePathIndex(result)
+			}
+			if doc != nil {
+				result++
+			}
+		}
+	}
+	return NodePathIndex(result)
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package overlay
+
+import (
+	"fmt"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/ytt/pkg/yamlmeta"
+	"github.com/k14s/ytt/pkg/yamltemplate"
+)
+
+const (
+	threadLeftDocSetsKey = "ytt.overlay.left_doc_sets_key"
+)
+
+// setLeftDocSets makes left documents available to overlay.lookup
+// for the duration of overlaying; returned func restores previous state
+// (overlay.apply may be nested within overlay functions)
+func setLeftDocSets(thread *starlark.Thread, docSets []*yamlmeta.DocumentSet) func() {
+	prevDocSets := thread.Local(threadLeftDocSetsKey)
+	thread.SetLocal(threadLeftDocSetsKey, docSets)
+	return func() { thread.SetLocal(threadLeftDocSetsKey, prevDocSets) }
+}
+
+func (b overlayModule) Lookup(
+	thread *starlark.Thread, f *starlark.Builtin,
+	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+	if args.Len() > 1 {
+		return starlark.None, fmt.Errorf("expected at most one argument")
+	}
+
+	leftDocSets, ok := thread.Local(threadLeftDocSetsKey).([]*yamlmeta.DocumentSet)
+	if !ok {
+		return starlark.None, fmt.Errorf("Expected to be called while overlaying documents " +
+			"(e.g. from matcher or replace functions within overlay documents)")
+	}
+
+	var expects *starlark.Value
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+		switch kwargName {
+		case MatchAnnotationKwargExpects:
+			expects = &kwarg[1]
+		default:
+			return starlark.None, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
+	var result []starlark.Value
+
+	if args.Len() == 0 {
+		if expects != nil {
+			return starlark.None, fmt.Errorf("Expected keyword argument '%s' "+
+				"to be used only with a matcher", MatchAnnotationKwargExpects)
+		}
+		for _, leftDocSet := range leftDocSets {
+			for _, item := range leftDocSet.Items {
+				result = append(result, yamltemplate.NewGoValueWithYAML(item.Value).AsStarlarkValue())
+			}
+		}
+		return starlark.NewList(result), nil
+	}
+
+	matcher := args.Index(0)
+	if _, ok := matcher.(starlark.Callable); !ok {
+		return starlark.None, fmt.Errorf("Expected matcher to be function, but was %s", matcher.Type())
+	}
+
+	docAnn := DocumentMatchAnnotation{
+		newDoc:  &yamlmeta.Document{},
+		thread:  thread,
+		matcher: &matcher,
+	}
+
+	idxs, matches, err := docAnn.MatchNodes(leftDocSets)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	if expects != nil {
+		err := MatchAnnotationExpectsKwarg{expects: expects, thread: thread}.Check(matches)
+		if err != nil {
+			return starlark.None, err
+		}
+	}
+
+	for _, idx := range idxs {
+		doc := leftDocSets[idx[0]].Items[idx[1]]
+		result = append(result, yamltemplate.NewGoValueWithYAML(doc.Value).AsStarlarkValue())
+	}
+
+	return starlark.NewList(result), nil
+}
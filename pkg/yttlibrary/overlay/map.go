@@ -4,6 +4,9 @@
 package overlay
 
 import (
+	"fmt"
+
+	"github.com/k14s/ytt/pkg/structmeta"
 	"github.com/k14s/ytt/pkg/yamlmeta"
 )
 
@@ -30,17 +33,20 @@ func (o OverlayOp) mergeMapItem(leftMap *yamlmeta.Map, newItem *yamlmeta.MapItem
 
 	if len(leftIdxs) == 0 {
 		// No need to traverse further
-		leftMap.Items = append(leftMap.Items, newItem)
-		return nil
+		item := newItem.DeepCopy()
+		leftMap.Items = append(leftMap.Items, item)
+		o.record(reportInserted, item.Key)
+		return o.copyIntoNewNode(item)
 	}
 
 	for _, leftIdx := range leftIdxs {
-		replace, err := o.apply(leftMap.Items[leftIdx].Value, newItem.Value, matchChildDefaults)
+		replace, err := o.withLeftPath(leftMap.Items[leftIdx].Key).apply(leftMap.Items[leftIdx].Value, newItem.Value, matchChildDefaults)
 		if err != nil {
 			return err
 		}
 		if replace {
 			leftMap.Items[leftIdx].Value = newItem.Value
+			o.record(reportMerged, leftMap.Items[leftIdx].Key)
 		}
 	}
 
@@ -63,6 +69,10 @@ func (o OverlayOp) removeMapItem(leftMap *yamlmeta.Map, newItem *yamlmeta.MapIte
 		return err
 	}
 
+	for _, leftIdx := range leftIdxs {
+		o.record(reportRemoved, leftMap.Items[leftIdx].Key)
+	}
+
 	for _, leftIdx := range leftIdxs {
 		leftMap.Items[leftIdx] = nil
 	}
@@ -102,14 +112,46 @@ func (o OverlayOp) replaceMapItem(leftMap *yamlmeta.Map, newItem *yamlmeta.MapIt
 		return err
 	}
 
+	// Collect matched items upfront since copying (moving) into
+	// replacement values may shift positions of left items
+	var leftItems []*yamlmeta.MapItem
 	for _, leftIdx := range leftIdxs {
-		newVal, err := replaceAnn.Value(leftMap.Items[leftIdx])
+		leftItems = append(leftItems, leftMap.Items[leftIdx])
+	}
+
+	for _, leftItem := range leftItems {
+		newVal, err := replaceAnn.Value(leftItem)
+		if err != nil {
+			return err
+		}
+
+		item := newItem.DeepCopy()
+		item.SetValue(newVal)
+
+		// Keep original key type when keys are written the same way
+		// (e.g. int key 200 replaced via data value 'key.200')
+		if fmt.Sprintf("%v", leftItem.Key) == fmt.Sprintf("%v", newItem.Key) {
+			item.Key = leftItem.Key
+		}
+
+		// Resolve sources while replaced item is still present
+		err = o.copyIntoNewNode(item)
 		if err != nil {
 			return err
 		}
 
-		leftMap.Items[leftIdx] = newItem.DeepCopy()
-		leftMap.Items[leftIdx].SetValue(newVal)
+		leftIdx := -1
+		for i, currItem := range leftMap.Items {
+			if currItem == leftItem {
+				leftIdx = i
+			}
+		}
+		if leftIdx == -1 {
+			return fmt.Errorf("Expected replaced map item '%s' to not be moved into its replacement", leftItem.Key)
+		}
+
+		leftMap.Items[leftIdx] = item
+		o.record(reportReplaced, item.Key)
 	}
 
 	return nil
@@ -147,7 +189,7 @@ func (o OverlayOp) assertMapItem(leftMap *yamlmeta.Map, newItem *yamlmeta.MapIte
 			return err
 		}
 
-		_, err = o.apply(leftMap.Items[leftIdx].Value, newItem.Value, matchChildDefaults)
+		_, err = o.withLeftPath(leftMap.Items[leftIdx].Key).apply(leftMap.Items[leftIdx].Value, newItem.Value, matchChildDefaults)
 		if err != nil {
 			return err
 		}
@@ -155,3 +197,49 @@ func (o OverlayOp) assertMapItem(leftMap *yamlmeta.Map, newItem *yamlmeta.MapIte
 
 	return nil
 }
+
+func (o OverlayOp) copyMapItem(leftMap *yamlmeta.Map, newItem *yamlmeta.MapItem,
+	op structmeta.AnnotationName, parentMatchChildDefaults MatchChildDefaultsAnnotation) error {
+
+	ann, err := NewMapItemMatchAnnotation(newItem, parentMatchChildDefaults, o.Thread)
+	if err != nil {
+		return err
+	}
+
+	copyAnn, err := NewCopyAnnotation(newItem, op, o.Thread)
+	if err != nil {
+		return err
+	}
+
+	leftIdxs, err := ann.Indexes(leftMap)
+	if err != nil {
+		if err, ok := err.(MatchAnnotationNumMatchError); ok && err.isConditional() {
+			return nil
+		}
+		return err
+	}
+
+	source, err := copyAnn.Source(o)
+	if err != nil {
+		return err
+	}
+
+	if len(leftIdxs) == 0 {
+		item := newItem.DeepCopy()
+		item.SetValue(source.Value())
+		leftMap.Items = append(leftMap.Items, item)
+		o.record(reportInserted, item.Key)
+	}
+
+	for _, leftIdx := range leftIdxs {
+		leftMap.Items[leftIdx].SetValue(source.Value())
+		o.record(reportReplaced, leftMap.Items[leftIdx].Key)
+	}
+
+	if copyAnn.IsMove() {
+		o.recordPath(reportRemoved, source.ReportPath())
+		return source.Remove()
+	}
+
+	return nil
+}
@@ -5,7 +5,6 @@ package overlay
 
 import (
 	"fmt"
-	"reflect"
 
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/k14s/ytt/pkg/filepos"
@@ -73,7 +72,7 @@ func (a MapItemMatchAnnotation) MatchNodes(leftMap *yamlmeta.Map) ([]int, []*fil
 		var matches []*filepos.Position
 
 		for i, item := range leftMap.Items {
-			if reflect.DeepEqual(item.Key, a.newItem.Key) {
+			if (Comparison{}).CompareMapKeys(item.Key, a.newItem.Key) {
 				leftIdxs = append(leftIdxs, i)
 				matches = append(matches, item.Position)
 			}
@@ -81,7 +80,7 @@ func (a MapItemMatchAnnotation) MatchNodes(leftMap *yamlmeta.Map) ([]int, []*fil
 		return leftIdxs, matches, nil
 	}
 
-	if _, ok := (*matcher).(starlark.String); ok {
+	if isMapKeyName(*matcher) {
 		matcherFunc, err := starlark.Call(a.thread, overlayModule{}.MapKey(),
 			starlark.Tuple{*matcher}, []starlark.Tuple{})
 		if err != nil {
@@ -122,6 +121,6 @@ func (a MapItemMatchAnnotation) MatchNodes(leftMap *yamlmeta.Map) ([]int, []*fil
 
 	default:
 		return nil, nil, fmt.Errorf("Expected '%s' annotation keyword argument 'by' "+
-			"to be either string (for map key) or function, but was %T", AnnotationMatch, typedVal)
+			"to be either map key (string, int, float or bool) or function, but was %T", AnnotationMatch, typedVal)
 	}
 }
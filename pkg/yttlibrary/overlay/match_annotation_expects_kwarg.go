@@ -49,6 +49,15 @@ func (a *MatchAnnotationExpectsKwarg) FillInDefaults(defaults MatchChildDefaults
 	if a.when == nil {
 		a.when = defaults.expects.when
 	}
+	if a.IsEmpty() && defaults.lenientMissing {
+		missingOK := starlark.Value(starlark.Bool(true))
+		a.missingOK = &missingOK
+	}
+}
+
+// IsEmpty indicates that none of expects, missing_ok or when were specified
+func (a MatchAnnotationExpectsKwarg) IsEmpty() bool {
+	return a.expects == nil && a.missingOK == nil && a.when == nil
 }
 
 func (a MatchAnnotationExpectsKwarg) Check(matches []*filepos.Position) error {
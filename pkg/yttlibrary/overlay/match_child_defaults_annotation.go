@@ -11,12 +11,18 @@ import (
 )
 
 type MatchChildDefaultsAnnotation struct {
-	expects MatchAnnotationExpectsKwarg
+	expects       MatchAnnotationExpectsKwarg
+	mergeStrategy MergeStrategyAnnotation
+
+	// lenientMissing makes matches without any explicit
+	// expectations default to missing_ok=True (strict_missing=False)
+	lenientMissing bool
 }
 
 func NewEmptyMatchChildDefaultsAnnotation() MatchChildDefaultsAnnotation {
 	return MatchChildDefaultsAnnotation{
-		expects: MatchAnnotationExpectsKwarg{},
+		expects:       MatchAnnotationExpectsKwarg{},
+		mergeStrategy: MergeStrategyAnnotation{},
 	}
 }
 
@@ -25,7 +31,8 @@ func NewMatchChildDefaultsAnnotation(node template.EvaluationNode,
 
 	annotation := MatchChildDefaultsAnnotation{
 		// TODO do we need to propagate thread?
-		expects: MatchAnnotationExpectsKwarg{},
+		expects:        MatchAnnotationExpectsKwarg{},
+		lenientMissing: parentMatchChildDefaults.lenientMissing,
 	}
 	kwargs := template.NewAnnotations(node).Kwargs(AnnotationMatchChildDefaults)
 
@@ -46,5 +53,12 @@ func NewMatchChildDefaultsAnnotation(node template.EvaluationNode,
 
 	annotation.expects.FillInDefaults(parentMatchChildDefaults)
 
+	mergeStrategy, err := NewMergeStrategyAnnotation(node, parentMatchChildDefaults.mergeStrategy)
+	if err != nil {
+		return annotation, err
+	}
+
+	annotation.mergeStrategy = mergeStrategy
+
 	return annotation, nil
 }
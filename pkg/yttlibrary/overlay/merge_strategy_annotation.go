@@ -0,0 +1,133 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package overlay
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/ytt/pkg/template"
+	tplcore "github.com/k14s/ytt/pkg/template/core"
+	"github.com/k14s/ytt/pkg/yamlmeta"
+)
+
+const (
+	MergeStrategyAnnotationKwargKey        string = "key"
+	MergeStrategyAnnotationKwargKubernetes string = "kubernetes"
+)
+
+// KubernetesMergeKeys lists keys used to match array items of well known
+// Kubernetes list fields (based on patchMergeKey used by strategic merge patch).
+// When multiple keys are listed, first key present in the overlay item is used
+// (e.g. container ports use containerPort, while service ports use port).
+var KubernetesMergeKeys = map[string][]string{
+	"containers":                {"name"},
+	"initContainers":            {"name"},
+	"ephemeralContainers":       {"name"},
+	"volumes":                   {"name"},
+	"volumeMounts":              {"mountPath"},
+	"volumeDevices":             {"devicePath"},
+	"env":                       {"name"},
+	"ports":                     {"containerPort", "port"},
+	"imagePullSecrets":          {"name"},
+	"secrets":                   {"name"},
+	"hostAliases":               {"ip"},
+	"topologySpreadConstraints": {"topologyKey"},
+	"ownerReferences":           {"uid"},
+	"conditions":                {"type"},
+}
+
+// MergeStrategyAnnotation specifies how array items are matched
+// when they do not have explicit @overlay/match annotation.
+// Explicit key only applies to items of the annotated node's array;
+// Kubernetes merge keys apply to all nested arrays.
+type MergeStrategyAnnotation struct {
+	keys       []string
+	kubernetes bool
+}
+
+func NewMergeStrategyAnnotation(node template.EvaluationNode,
+	parent MergeStrategyAnnotation) (MergeStrategyAnnotation, error) {
+
+	annotation := MergeStrategyAnnotation{kubernetes: parent.kubernetes}
+	anns := template.NewAnnotations(node)
+
+	if anns.Has(AnnotationMergeStrategy) {
+		kwargs := anns.Kwargs(AnnotationMergeStrategy)
+		if len(kwargs) == 0 {
+			return annotation, fmt.Errorf("Expected '%s' annotation to have "+
+				"at least one keyword argument (key=..., kubernetes=...)", AnnotationMergeStrategy)
+		}
+
+		for _, kwarg := range kwargs {
+			kwargName := string(kwarg[0].(starlark.String))
+			switch kwargName {
+			case MergeStrategyAnnotationKwargKey:
+				key, err := tplcore.NewStarlarkValue(kwarg[1]).AsString()
+				if err != nil {
+					return annotation, fmt.Errorf("Expected '%s' annotation keyword argument '%s' "+
+						"to be a string: %s", AnnotationMergeStrategy, kwargName, err)
+				}
+				annotation.keys = []string{key}
+
+			case MergeStrategyAnnotationKwargKubernetes:
+				kubernetes, err := tplcore.NewStarlarkValue(kwarg[1]).AsBool()
+				if err != nil {
+					return annotation, fmt.Errorf("Expected '%s' annotation keyword argument '%s' "+
+						"to be a boolean: %s", AnnotationMergeStrategy, kwargName, err)
+				}
+				annotation.kubernetes = kubernetes
+
+			default:
+				return annotation, fmt.Errorf(
+					"Unknown '%s' annotation keyword argument '%s'", AnnotationMergeStrategy, kwargName)
+			}
+		}
+
+		if len(annotation.keys) > 0 {
+			if _, isArray := node.GetValues()[0].(*yamlmeta.Array); !isArray {
+				return annotation, fmt.Errorf("Expected '%s' annotation keyword argument '%s' "+
+					"to be used on a node with array value, but was %T",
+					AnnotationMergeStrategy, MergeStrategyAnnotationKwargKey, node.GetValues()[0])
+			}
+		}
+	}
+
+	if len(annotation.keys) == 0 && annotation.kubernetes {
+		if typedItem, ok := node.(*yamlmeta.MapItem); ok {
+			if _, isArray := typedItem.Value.(*yamlmeta.Array); isArray {
+				if key, ok := typedItem.Key.(string); ok {
+					annotation.keys = KubernetesMergeKeys[key]
+				}
+			}
+		}
+	}
+
+	return annotation, nil
+}
+
+func (a MergeStrategyAnnotation) HasKeys() bool { return len(a.keys) > 0 }
+
+// Matcher returns map key to match given array item by
+func (a MergeStrategyAnnotation) Matcher(newItem *yamlmeta.ArrayItem) (*starlark.Value, error) {
+	typedMap, ok := newItem.Value.(*yamlmeta.Map)
+	if !ok {
+		return nil, fmt.Errorf("Expected array item to be a map to be matched via '%s' "+
+			"annotation, but was %T", AnnotationMergeStrategy, newItem.Value)
+	}
+
+	for _, key := range a.keys {
+		for _, item := range typedMap.Items {
+			if reflect.DeepEqual(item.Key, key) {
+				var matcher starlark.Value = starlark.String(key)
+				return &matcher, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("Expected array item to have key '%s' to be matched via '%s' annotation",
+		strings.Join(a.keys, "' or '"), AnnotationMergeStrategy)
+}
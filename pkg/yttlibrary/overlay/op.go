@@ -19,13 +19,35 @@ type OverlayOp struct {
 	Thread *starlark.Thread
 
 	ExactMatch bool
+
+	// AllowMissing lets matches without explicit
+	// expectations find no nodes (as if missing_ok=True)
+	AllowMissing bool
+
+	// Report (if set) collects nodes affected by the overlay
+	Report *Report
+
+	// Left documents and node currently being overlaid
+	// (used for resolving sources of copy and move operations)
+	leftDocSets  []*yamlmeta.DocumentSet
+	leftRoot     interface{}
+	leftRootPath NodePath
+
+	// Path of the left node currently being overlaid
+	// (relative to the left value; used for reports)
+	leftPath NodePath
 }
 
 func (o OverlayOp) Apply() (interface{}, error) {
 	leftObj := yamlmeta.NewASTFromInterface(o.Left)
 	rightObj := yamlmeta.NewASTFromInterface(o.Right)
 
-	_, err := o.apply(leftObj, rightObj, NewEmptyMatchChildDefaultsAnnotation())
+	o.leftRoot = leftObj
+
+	matchChildDefaults := NewEmptyMatchChildDefaultsAnnotation()
+	matchChildDefaults.lenientMissing = o.AllowMissing
+
+	_, err := o.apply(leftObj, rightObj, matchChildDefaults)
 	if err != nil {
 		return nil, err
 	}
@@ -78,6 +100,8 @@ func (o OverlayOp) apply(left, right interface{}, parentMatchChildDefaults Match
 					err = o.replaceMapItem(typedLeft, item, parentMatchChildDefaults)
 				case AnnotationAssert:
 					err = o.assertMapItem(typedLeft, item, parentMatchChildDefaults)
+				case AnnotationCopy, AnnotationMove:
+					err = o.copyMapItem(typedLeft, item, op, parentMatchChildDefaults)
 				default:
 					err = fmt.Errorf("Overlay op %s is not supported on map item", op)
 				}
@@ -115,6 +139,8 @@ func (o OverlayOp) apply(left, right interface{}, parentMatchChildDefaults Match
 					err = o.appendArrayItem(typedLeft, item)
 				case AnnotationAssert:
 					err = o.assertArrayItem(typedLeft, item, parentMatchChildDefaults)
+				case AnnotationCopy, AnnotationMove:
+					err = o.copyArrayItem(typedLeft, item, op, parentMatchChildDefaults)
 				default:
 					err = fmt.Errorf("Overlay op %s is not supported on array item", op)
 				}
@@ -138,9 +164,21 @@ func (o OverlayOp) applyDocSet(
 	typedLeft []*yamlmeta.DocumentSet, typedRight *yamlmeta.DocumentSet,
 	parentMatchChildDefaults MatchChildDefaultsAnnotation) (bool, error) {
 
+	o.leftDocSets = typedLeft
+
+	defer setLeftDocSets(o.Thread, typedLeft)()
+
 	for _, doc := range typedRight.Items {
 		doc := doc.DeepCopy()
 
+		applies, err := o.docApplies(typedLeft, doc)
+		if err != nil {
+			return false, fmt.Errorf("Document on %s: %s", doc.Position.AsString(), err)
+		}
+		if !applies {
+			continue
+		}
+
 		op, err := whichOp(doc)
 		if err == nil {
 			switch op {
@@ -168,6 +206,74 @@ func (o OverlayOp) applyDocSet(
 	return false, nil
 }
 
+func (o OverlayOp) docApplies(typedLeft []*yamlmeta.DocumentSet, doc *yamlmeta.Document) (bool, error) {
+	ann, err := NewApplyIfAnnotation(doc, o.Thread)
+	if err != nil {
+		return false, err
+	}
+	return ann.Check(typedLeft)
+}
+
+// copyIntoNewNode resolves copy and move operations within newly
+// added nodes as such nodes are added without further traversal
+func (o OverlayOp) copyIntoNewNode(node yamlmeta.Node) error {
+	for _, childVal := range node.GetValues() {
+		childNode, ok := childVal.(yamlmeta.Node)
+		if !ok {
+			continue
+		}
+
+		op, err := whichOp(childNode)
+		if err != nil {
+			return err
+		}
+
+		if op == AnnotationCopy || op == AnnotationMove {
+			copyAnn, err := NewCopyAnnotation(childNode, op, o.Thread)
+			if err != nil {
+				return err
+			}
+
+			source, err := copyAnn.Source(o)
+			if err != nil {
+				return err
+			}
+
+			childNode.SetValue(source.Value())
+
+			if copyAnn.IsMove() {
+				o.recordPath(reportRemoved, source.ReportPath())
+				err := source.Remove()
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		err = o.copyIntoNewNode(childNode)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o OverlayOp) withLeftRoot(root interface{}, rootPath NodePath) OverlayOp {
+	o.leftRoot = root
+	o.leftRootPath = rootPath
+	o.leftPath = rootPath
+	return o
+}
+
+// withLeftPath descends into a child (identified
+// by map key or array index) of currently overlaid left node
+func (o OverlayOp) withLeftPath(piece interface{}) OverlayOp {
+	o.leftPath = o.leftPath.Append(piece)
+	return o
+}
+
 func (o OverlayOp) removeOverlayAnns(val interface{}) {
 	node, ok := val.(yamlmeta.Node)
 	if !ok {
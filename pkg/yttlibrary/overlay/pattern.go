@@ -0,0 +1,78 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package overlay
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/k14s/ytt/pkg/yamlmeta"
+)
+
+type PatternCompiler func(string) (*regexp.Regexp, error)
+
+// CompileRegexpPattern compiles RE2 pattern as is (unanchored,
+// consistent with regexp.match from @ytt:regexp)
+func CompileRegexpPattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("Expected '%s' to be a valid regular expression: %s", pattern, err)
+	}
+	return re, nil
+}
+
+// CompileGlobPattern compiles glob pattern that has to match whole value.
+// Supported wildcards: '*' (any sequence of characters), '?' (any single character)
+func CompileGlobPattern(pattern string) (*regexp.Regexp, error) {
+	var result strings.Builder
+
+	result.WriteString("^")
+	for _, ch := range pattern {
+		switch ch {
+		case '*':
+			result.WriteString(".*")
+		case '?':
+			result.WriteString(".")
+		default:
+			result.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	result.WriteString("$")
+
+	re, err := regexp.Compile(result.String())
+	if err != nil {
+		return nil, fmt.Errorf("Expected '%s' to be a valid glob: %s", pattern, err)
+	}
+	return re, nil
+}
+
+// compilePatterns compiles all string leafs as patterns so that
+// invalid patterns are reported instead of silently not matching
+func (c PatternCompiler) compilePatterns(val interface{}, result map[string]*regexp.Regexp) error {
+	switch typedVal := val.(type) {
+	case string:
+		if _, found := result[typedVal]; found {
+			return nil
+		}
+		re, err := c(typedVal)
+		if err != nil {
+			return err
+		}
+		result[typedVal] = re
+		return nil
+
+	case yamlmeta.Node:
+		for _, childVal := range typedVal.GetValues() {
+			err := c.compilePatterns(childVal, result)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return nil
+	}
+}
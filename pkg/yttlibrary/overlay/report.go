@@ -0,0 +1,73 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package overlay
+
+import (
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/starlarkstruct"
+)
+
+// Report collects paths of left nodes affected by overlay operations.
+// Paths are relative to the left value (for document sets,
+// first path piece is an index of the document).
+type Report struct {
+	Merged   []NodePath
+	Replaced []NodePath
+	Inserted []NodePath
+	Removed  []NodePath
+}
+
+type reportKind int
+
+const (
+	reportMerged reportKind = iota
+	reportReplaced
+	reportInserted
+	reportRemoved
+)
+
+// AsStarlarkValue returns struct with overlay result
+// and lists of path strings for each kind of change
+func (r *Report) AsStarlarkValue(result starlark.Value) starlark.Value {
+	asList := func(paths []NodePath) *starlark.List {
+		result := []starlark.Value{}
+		for _, path := range paths {
+			result = append(result, starlark.String(path.String()))
+		}
+		return starlark.NewList(result)
+	}
+
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"result":   result,
+		"merged":   asList(r.Merged),
+		"replaced": asList(r.Replaced),
+		"inserted": asList(r.Inserted),
+		"removed":  asList(r.Removed),
+	})
+}
+
+func (r *Report) record(kind reportKind, path NodePath) {
+	switch kind {
+	case reportMerged:
+		r.Merged = append(r.Merged, path)
+	case reportReplaced:
+		r.Replaced = append(r.Replaced, path)
+	case reportInserted:
+		r.Inserted = append(r.Inserted, path)
+	case reportRemoved:
+		r.Removed = append(r.Removed, path)
+	}
+}
+
+// record adds child (identified by map key or array index) of currently
+// overlaid left node to a report if reporting was requested
+func (o OverlayOp) record(kind reportKind, piece interface{}) {
+	o.recordPath(kind, o.leftPath.Append(piece))
+}
+
+func (o OverlayOp) recordPath(kind reportKind, path NodePath) {
+	if o.Report != nil {
+		o.Report.record(kind, path)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package overlay
+
+import (
+	"fmt"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/ytt/pkg/template"
+	tplcore "github.com/k14s/ytt/pkg/template/core"
+	"github.com/k14s/ytt/pkg/yamlmeta"
+)
+
+const (
+	StageAnnotationKwargName  string = "name"
+	StageAnnotationKwargAfter string = "after"
+
+	// DefaultStageName is assigned to overlay documents without @overlay/stage
+	DefaultStageName = "default"
+)
+
+// StageAnnotation groups overlay documents so that
+// they could be ordered independently of file order
+type StageAnnotation struct {
+	name  string
+	after []string
+}
+
+func NewStageAnnotation(newDoc *yamlmeta.Document) (StageAnnotation, error) {
+	annotation := StageAnnotation{name: DefaultStageName}
+	anns := template.NewAnnotations(newDoc)
+
+	if !anns.Has(AnnotationStage) {
+		return annotation, nil
+	}
+
+	annotation.name = ""
+
+	for _, kwarg := range anns.Kwargs(AnnotationStage) {
+		kwargName := string(kwarg[0].(starlark.String))
+		switch kwargName {
+		case StageAnnotationKwargName:
+			name, err := tplcore.NewStarlarkValue(kwarg[1]).AsString()
+			if err != nil {
+				return annotation, fmt.Errorf("Expected '%s' annotation keyword argument '%s' "+
+					"to be a string: %s", AnnotationStage, kwargName, err)
+			}
+			annotation.name = name
+
+		case StageAnnotationKwargAfter:
+			after, err := stageNames(kwarg[1])
+			if err != nil {
+				return annotation, fmt.Errorf("Expected '%s' annotation keyword argument '%s' "+
+					"to be a string or list of strings: %s", AnnotationStage, kwargName, err)
+			}
+			annotation.after = after
+
+		default:
+			return annotation, fmt.Errorf(
+				"Unknown '%s' annotation keyword argument '%s'", AnnotationStage, kwargName)
+		}
+	}
+
+	if len(annotation.name) == 0 {
+		return annotation, fmt.Errorf("Expected '%s' annotation "+
+			"keyword argument '%s' to be non-empty", AnnotationStage, StageAnnotationKwargName)
+	}
+
+	for _, after := range annotation.after {
+		if after == annotation.name {
+			return annotation, fmt.Errorf("Expected stage '%s' to not be ordered after itself", after)
+		}
+	}
+
+	return annotation, nil
+}
+
+func (a StageAnnotation) Name() string    { return a.name }
+func (a StageAnnotation) After() []string { return a.after }
+
+func stageNames(val starlark.Value) ([]string, error) {
+	switch typedVal := val.(type) {
+	case starlark.String:
+		return []string{string(typedVal)}, nil
+
+	case *starlark.List, starlark.Tuple:
+		var result []string
+		for _, item := range tplcore.NewStarlarkValue(val).AsGoValue().([]interface{}) {
+			typedItem, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("Expected list item to be a string, but was %T", item)
+			}
+			result = append(result, typedItem)
+		}
+		return result, nil
+
+	default:
+		return nil, fmt.Errorf("Expected string or list, but was %s", val.Type())
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package overlay
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/ytt/pkg/filepos"
+	"github.com/k14s/ytt/pkg/files"
+	"github.com/k14s/ytt/pkg/template"
+	tplcore "github.com/k14s/ytt/pkg/template/core"
+	"github.com/k14s/ytt/pkg/yamlmeta"
+)
+
+const (
+	TextAnnotationKwargPath string = "path"
+
+	TextOpInsertBefore = "insert_before"
+	TextOpInsertAfter  = "insert_after"
+	TextOpReplace      = "replace"
+	TextOpRemove       = "remove"
+
+	textOpKeyText      = "text"
+	textOpKeyWith      = "with"
+	textOpKeyMissingOK = "missing_ok"
+)
+
+// TextOverlay is a set of line oriented operations
+// (specified in a YAML document annotated with @overlay/text)
+// applied to text files matching path
+type TextOverlay struct {
+	path *regexp.Regexp
+	ops  []TextOp
+}
+
+type TextOp struct {
+	kind      string
+	pattern   *regexp.Regexp
+	text      string
+	missingOK bool
+	position  *filepos.Position
+}
+
+func NewTextOverlay(doc *yamlmeta.Document) (TextOverlay, error) {
+	overlay := TextOverlay{}
+
+	for _, kwarg := range template.NewAnnotations(doc).Kwargs(AnnotationText) {
+		kwargName := string(kwarg[0].(starlark.String))
+		switch kwargName {
+		case TextAnnotationKwargPath:
+			path, err := tplcore.NewStarlarkValue(kwarg[1]).AsString()
+			if err != nil {
+				return overlay, fmt.Errorf("Expected '%s' annotation keyword argument '%s' "+
+					"to be a string: %s", AnnotationText, kwargName, err)
+			}
+			overlay.path, err = files.CompilePathGlob(path)
+			if err != nil {
+				return overlay, err
+			}
+		default:
+			return overlay, fmt.Errorf(
+				"Unknown '%s' annotation keyword argument '%s'", AnnotationText, kwargName)
+		}
+	}
+
+	if overlay.path == nil {
+		return overlay, fmt.Errorf("Expected '%s' annotation "+
+			"keyword argument '%s' to be specified", AnnotationText, TextAnnotationKwargPath)
+	}
+
+	typedArray, ok := doc.Value.(*yamlmeta.Array)
+	if !ok {
+		return overlay, fmt.Errorf("Expected '%s' document to be an array "+
+			"of operations, but was %T", AnnotationText, doc.Value)
+	}
+
+	for _, item := range typedArray.Items {
+		op, err := NewTextOp(item)
+		if err != nil {
+			return overlay, fmt.Errorf("Operation on %s: %s", item.Position.AsString(), err)
+		}
+		overlay.ops = append(overlay.ops, op)
+	}
+
+	return overlay, nil
+}
+
+func (o TextOverlay) Matches(path string) bool { return o.path.MatchString(path) }
+
+func (o TextOverlay) Apply(text string) (string, error) {
+	for _, op := range o.ops {
+		var err error
+		text, err = op.Apply(text)
+		if err != nil {
+			return "", fmt.Errorf("Operation on %s: %s", op.position.AsString(), err)
+		}
+	}
+	return text, nil
+}
+
+func NewTextOp(item *yamlmeta.ArrayItem) (TextOp, error) {
+	op := TextOp{position: item.Position}
+
+	typedMap, ok := item.Value.(*yamlmeta.Map)
+	if !ok {
+		return op, fmt.Errorf("Expected operation to be a map, but was %T", item.Value)
+	}
+
+	var hasText, hasWith bool
+
+	for _, mapItem := range typedMap.Items {
+		key, ok := mapItem.Key.(string)
+		if !ok {
+			return op, fmt.Errorf("Expected operation key to be a string, but was %T", mapItem.Key)
+		}
+
+		switch key {
+		case TextOpInsertBefore, TextOpInsertAfter, TextOpReplace, TextOpRemove:
+			if len(op.kind) > 0 {
+				return op, fmt.Errorf("Expected to find only one of operations "+
+					"(%s, %s, %s, %s)", TextOpInsertBefore, TextOpInsertAfter, TextOpReplace, TextOpRemove)
+			}
+			pattern, ok := mapItem.Value.(string)
+			if !ok {
+				return op, fmt.Errorf("Expected '%s' to be a regular expression string, but was %T", key, mapItem.Value)
+			}
+			var err error
+			op.pattern, err = CompileRegexpPattern(pattern)
+			if err != nil {
+				return op, err
+			}
+			op.kind = key
+
+		case textOpKeyText, textOpKeyWith:
+			text, ok := mapItem.Value.(string)
+			if !ok {
+				return op, fmt.Errorf("Expected '%s' to be a string, but was %T", key, mapItem.Value)
+			}
+			op.text = text
+			hasText = hasText || key == textOpKeyText
+			hasWith = hasWith || key == textOpKeyWith
+
+		case textOpKeyMissingOK:
+			missingOK, ok := mapItem.Value.(bool)
+			if !ok {
+				return op, fmt.Errorf("Expected '%s' to be a boolean, but was %T", key, mapItem.Value)
+			}
+			op.missingOK = missingOK
+
+		default:
+			return op, fmt.Errorf("Unknown operation key '%s'", key)
+		}
+	}
+
+	switch op.kind {
+	case "":
+		return op, fmt.Errorf("Expected to find one of operations "+
+			"(%s, %s, %s, %s)", TextOpInsertBefore, TextOpInsertAfter, TextOpReplace, TextOpRemove)
+	case TextOpInsertBefore, TextOpInsertAfter:
+		if !hasText || hasWith {
+			return op, fmt.Errorf("Expected operation '%s' to specify '%s' (and not '%s')", op.kind, textOpKeyText, textOpKeyWith)
+		}
+	case TextOpReplace:
+		if !hasWith || hasText {
+			return op, fmt.Errorf("Expected operation '%s' to specify '%s' (and not '%s')", op.kind, textOpKeyWith, textOpKeyText)
+		}
+	case TextOpRemove:
+		if hasText || hasWith {
+			return op, fmt.Errorf("Expected operation '%s' to not specify '%s' or '%s'", op.kind, textOpKeyText, textOpKeyWith)
+		}
+	}
+
+	return op, nil
+}
+
+// Apply executes operation on each line matching the pattern.
+// Replace substitutes matched portion of the line (supports $1-style references).
+func (op TextOp) Apply(text string) (string, error) {
+	hasTrailingNewline := strings.HasSuffix(text, "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if len(text) == 0 {
+		lines = nil
+	}
+
+	var result []string
+	var matched int
+
+	for _, line := range lines {
+		if !op.pattern.MatchString(line) {
+			result = append(result, line)
+			continue
+		}
+
+		matched++
+
+		switch op.kind {
+		case TextOpInsertBefore:
+			result = append(result, op.textLines()...)
+			result = append(result, line)
+		case TextOpInsertAfter:
+			result = append(result, line)
+			result = append(result, op.textLines()...)
+		case TextOpReplace:
+			result = append(result, op.pattern.ReplaceAllString(line, op.text))
+		case TextOpRemove:
+			// do not include line
+		default:
+			panic(fmt.Sprintf("Unknown text operation '%s'", op.kind))
+		}
+	}
+
+	if matched == 0 && !op.missingOK {
+		return "", fmt.Errorf("Expected '%s' pattern '%s' to match at least one line, but did not",
+			op.kind, op.pattern.String())
+	}
+
+	resultText := strings.Join(result, "\n")
+	if hasTrailingNewline && len(result) > 0 {
+		resultText += "\n"
+	}
+
+	return resultText, nil
+}
+
+func (op TextOp) textLines() []string {
+	return strings.Split(strings.TrimSuffix(op.text, "\n"), "\n")
+}
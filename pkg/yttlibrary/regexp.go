@@ -17,13 +17,24 @@ var (
 		"regexp": &starlarkstruct.Module{
 			Name: "regexp",
 			Members: starlark.StringDict{
-				"match":   starlark.NewBuiltin("regexp.match", core.ErrWrapper(regexpModule{}.Match)),
-				"replace": starlark.NewBuiltin("regexp.replace", core.ErrWrapper(regexpModule{}.Replace)),
+				"match":         starlark.NewBuiltin("regexp.match", core.ErrWrapper(regexpModule{}.Match)),
+				"replace":       starlark.NewBuiltin("regexp.replace", core.ErrWrapper(regexpModule{}.Replace)),
+				"find":          starlark.NewBuiltin("regexp.find", core.ErrWrapper(regexpModule{}.Find)),
+				"find_all":      starlark.NewBuiltin("regexp.find_all", core.ErrWrapper(regexpModule{}.FindAll)),
+				"find_submatch": starlark.NewBuiltin("regexp.find_submatch", core.ErrWrapper(regexpModule{}.FindSubmatch)),
+				"split":         starlark.NewBuiltin("regexp.split", core.ErrWrapper(regexpModule{}.Split)),
 			},
 		},
 	}
 )
 
+const (
+	threadRegexpCacheKey = "ytt.regexp.cache_key"
+
+	// Limits memory used by dynamically built patterns
+	regexpCacheMaxSize = 1000
+)
+
 type regexpModule struct{}
 
 func (b regexpModule) Match(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -41,12 +52,12 @@ func (b regexpModule) Match(thread *starlark.Thread, f *starlark.Builtin, args s
 		return starlark.None, err
 	}
 
-	matched, err := regexp.MatchString(pattern, target)
+	re, err := b.compile(thread, pattern)
 	if err != nil {
 		return starlark.None, err
 	}
 
-	return starlark.Bool(matched), nil
+	return starlark.Bool(re.MatchString(target)), nil
 }
 
 func (b regexpModule) Replace(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -59,7 +70,7 @@ func (b regexpModule) Replace(thread *starlark.Thread, f *starlark.Builtin, args
 		return starlark.None, err
 	}
 
-	re, err := regexp.Compile(pattern)
+	re, err := b.compile(thread, pattern)
 	if err != nil {
 		return starlark.None, err
 	}
@@ -119,3 +130,151 @@ func (b regexpModule) replaceLambda(thread *starlark.Thread, re *regexp.Regexp,
 
 	return starlark.String(newString), nil
 }
+
+// Find returns leftmost match or None if there is no match
+func (b regexpModule) Find(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	re, target, err := b.patternAndTarget(thread, args)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	loc := re.FindStringIndex(target)
+	if loc == nil {
+		return starlark.None, nil
+	}
+
+	return starlark.String(target[loc[0]:loc[1]]), nil
+}
+
+// FindAll returns list of all non-overlapping matches
+func (b regexpModule) FindAll(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	re, target, err := b.patternAndTarget(thread, args)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	result := []starlark.Value{}
+	for _, match := range re.FindAllString(target, -1) {
+		result = append(result, starlark.String(match))
+	}
+
+	return starlark.NewList(result), nil
+}
+
+// FindSubmatch returns groups of leftmost match as a dict (named groups
+// are keyed by name, others by their number) or None if there is no match.
+// Groups that did not participate in a match are set to None.
+func (b regexpModule) FindSubmatch(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	re, target, err := b.patternAndTarget(thread, args)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	loc := re.FindStringSubmatchIndex(target)
+	if loc == nil {
+		return starlark.None, nil
+	}
+
+	result := starlark.NewDict(re.NumSubexp())
+
+	for i, name := range re.SubexpNames() {
+		if i == 0 {
+			continue // whole match is available via regexp.find
+		}
+
+		var key starlark.Value = starlark.MakeInt(i)
+		if len(name) > 0 {
+			key = starlark.String(name)
+		}
+
+		var val starlark.Value = starlark.None
+		if loc[2*i] >= 0 {
+			val = starlark.String(target[loc[2*i]:loc[2*i+1]])
+		}
+
+		err := result.SetKey(key, val)
+		if err != nil {
+			return starlark.None, err
+		}
+	}
+
+	return result, nil
+}
+
+// Split splits string around matches; if keyword argument n is
+// non-negative, at most n pieces are returned (last piece is unsplit remainder)
+func (b regexpModule) Split(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	re, target, err := b.patternAndTarget(thread, args)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	n := -1
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "n":
+			n, err = starlark.AsInt32(kwarg[1])
+			if err != nil {
+				return starlark.None, fmt.Errorf("expected keyword argument 'n' to be an int: %s", err)
+			}
+		default:
+			return starlark.None, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
+	result := []starlark.Value{}
+	for _, piece := range re.Split(target, n) {
+		result = append(result, starlark.String(piece))
+	}
+
+	return starlark.NewList(result), nil
+}
+
+func (b regexpModule) patternAndTarget(thread *starlark.Thread, args starlark.Tuple) (*regexp.Regexp, string, error) {
+	if args.Len() != 2 {
+		return nil, "", fmt.Errorf("expected exactly two arguments")
+	}
+
+	pattern, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return nil, "", err
+	}
+
+	target, err := core.NewStarlarkValue(args.Index(1)).AsString()
+	if err != nil {
+		return nil, "", err
+	}
+
+	re, err := b.compile(thread, pattern)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return re, target, nil
+}
+
+// compile returns compiled pattern reusing previously compiled
+// patterns within the same thread (e.g. when called in a loop)
+func (b regexpModule) compile(thread *starlark.Thread, pattern string) (*regexp.Regexp, error) {
+	cache, ok := thread.Local(threadRegexpCacheKey).(map[string]*regexp.Regexp)
+	if !ok || len(cache) >= regexpCacheMaxSize {
+		cache = map[string]*regexp.Regexp{}
+		thread.SetLocal(threadRegexpCacheKey, cache)
+	}
+
+	if re, found := cache[pattern]; found {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	cache[pattern] = re
+
+	return re, nil
+}
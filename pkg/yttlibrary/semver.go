@@ -0,0 +1,287 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package yttlibrary
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/k14s/starlark-go/syntax"
+	"github.com/k14s/ytt/pkg/template/core"
+)
+
+var (
+	SemverAPI = starlark.StringDict{
+		"semver": &starlarkstruct.Module{
+			Name: "semver",
+			Members: starlark.StringDict{
+				"parse":      starlark.NewBuiltin("semver.parse", core.ErrWrapper(semverModule{}.Parse)),
+				"compare":    starlark.NewBuiltin("semver.compare", core.ErrWrapper(semverModule{}.Compare)),
+				"satisfies":  starlark.NewBuiltin("semver.satisfies", core.ErrWrapper(semverModule{}.Satisfies)),
+				"max":        starlark.NewBuiltin("semver.max", core.ErrWrapper(semverModule{}.Max)),
+				"bump_major": starlark.NewBuiltin("semver.bump_major", core.ErrWrapper(semverModule{}.BumpMajor)),
+				"bump_minor": starlark.NewBuiltin("semver.bump_minor", core.ErrWrapper(semverModule{}.BumpMinor)),
+				"bump_patch": starlark.NewBuiltin("semver.bump_patch", core.ErrWrapper(semverModule{}.BumpPatch)),
+			},
+		},
+	}
+
+	semverConstraintOpRegexp = regexp.MustCompile(`^(=|!=|>=|<=|>|<|~>)$`)
+)
+
+type semverModule struct{}
+
+func (b semverModule) Parse(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	return b.versionArg(args.Index(0))
+}
+
+// Compare returns -1, 0 or 1 (build metadata is ignored)
+func (b semverModule) Compare(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 2 {
+		return starlark.None, fmt.Errorf("expected exactly 2 arguments")
+	}
+
+	left, err := b.versionArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	right, err := b.versionArg(args.Index(1))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.MakeInt(left.version.Compare(right.version)), nil
+}
+
+// Satisfies checks version against constraints; constraints are separated
+// by spaces or commas (all must match), alternatives are separated by '||'
+// (e.g. '>=1.18 <1.21 || >=2.0.0')
+func (b semverModule) Satisfies(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 2 {
+		return starlark.None, fmt.Errorf("expected exactly 2 arguments")
+	}
+
+	ver, err := b.versionArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	constraintStr, err := core.NewStarlarkValue(args.Index(1)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	alternatives, err := b.parseConstraint(constraintStr)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	for _, constraints := range alternatives {
+		if constraints.Check(ver.version) {
+			return starlark.Bool(true), nil
+		}
+	}
+
+	return starlark.Bool(false), nil
+}
+
+func (b semverModule) Max(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	iterable, ok := args.Index(0).(starlark.Iterable)
+	if !ok {
+		return starlark.None, fmt.Errorf("expected argument to be a list, but was %s", args.Index(0).Type())
+	}
+
+	var result *SemverValue
+
+	iter := iterable.Iterate()
+	defer iter.Done()
+
+	var item starlark.Value
+	for iter.Next(&item) {
+		ver, err := b.versionArg(item)
+		if err != nil {
+			return starlark.None, err
+		}
+		if result == nil || ver.version.GreaterThan(result.version) {
+			result = ver
+		}
+	}
+
+	if result == nil {
+		return starlark.None, fmt.Errorf("expected at least one version")
+	}
+
+	return result, nil
+}
+
+// BumpMajor returns next major version (e.g. 1.2.3 to 2.0.0);
+// pre-release of a major version (e.g. 2.0.0-rc.1) is bumped to its release
+func (b semverModule) BumpMajor(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return b.bump(args, func(major, minor, patch int64, prerelease bool) (int64, int64, int64) {
+		if prerelease && minor == 0 && patch == 0 {
+			return major, 0, 0
+		}
+		return major + 1, 0, 0
+	})
+}
+
+// BumpMinor returns next minor version (e.g. 1.2.3 to 1.3.0);
+// pre-release of a minor version (e.g. 1.3.0-rc.1) is bumped to its release
+func (b semverModule) BumpMinor(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return b.bump(args, func(major, minor, patch int64, prerelease bool) (int64, int64, int64) {
+		if prerelease && patch == 0 {
+			return major, minor, 0
+		}
+		return major, minor + 1, 0
+	})
+}
+
+// BumpPatch returns next patch version (e.g. 1.2.3 to 1.2.4);
+// pre-release (e.g. 1.2.4-rc.1) is bumped to its release
+func (b semverModule) BumpPatch(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return b.bump(args, func(major, minor, patch int64, prerelease bool) (int64, int64, int64) {
+		if prerelease {
+			return major, minor, patch
+		}
+		return major, minor, patch + 1
+	})
+}
+
+func (b semverModule) bump(args starlark.Tuple,
+	nextFunc func(major, minor, patch int64, prerelease bool) (int64, int64, int64)) (starlark.Value, error) {
+
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	ver, err := b.versionArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	segments := ver.version.Segments64()
+	major, minor, patch := nextFunc(segments[0], segments[1], segments[2], len(ver.version.Prerelease()) > 0)
+
+	next, err := parseSemver(fmt.Sprintf("%d.%d.%d", major, minor, patch))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return &SemverValue{next}, nil
+}
+
+// versionArg accepts either semver.version value or a string;
+// strings may be prefixed with 'v' (e.g. v1.18.2)
+func (b semverModule) versionArg(val starlark.Value) (*SemverValue, error) {
+	if typedVal, ok := val.(*SemverValue); ok {
+		return typedVal, nil
+	}
+
+	str, err := core.NewStarlarkValue(val).AsString()
+	if err != nil {
+		return nil, fmt.Errorf("expected version to be a string or semver.version, but was %s", val.Type())
+	}
+
+	ver, err := parseSemver(strings.TrimPrefix(str, "v"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &SemverValue{ver}, nil
+}
+
+func (b semverModule) parseConstraint(str string) ([]semver.Constraints, error) {
+	var result []semver.Constraints
+
+	for _, alternative := range strings.Split(str, "||") {
+		var pieces []string
+
+		for _, field := range strings.Fields(strings.Replace(alternative, ",", " ", -1)) {
+			// Allow operator to be separated from a version (e.g. '>= 1.18')
+			if len(pieces) > 0 && semverConstraintOpRegexp.MatchString(pieces[len(pieces)-1]) {
+				pieces[len(pieces)-1] += field
+			} else {
+				pieces = append(pieces, field)
+			}
+		}
+
+		if len(pieces) == 0 {
+			return nil, fmt.Errorf("expected version constraint '%s' to not have empty alternatives", str)
+		}
+
+		constraints, err := semver.NewConstraint(strings.Join(pieces, ","))
+		if err != nil {
+			return nil, fmt.Errorf("expected '%s' to be a valid version constraint (e.g. '>=1.18 <1.21'): %s", str, err)
+		}
+
+		result = append(result, constraints)
+	}
+
+	return result, nil
+}
+
+// SemverValue represents semantic version.
+// Versions are ordered according to semver precedence
+// (build metadata is ignored) and converted to strings in YAML.
+type SemverValue struct {
+	version *semver.Version
+}
+
+var _ starlark.HasAttrs = &SemverValue{}
+var _ starlark.Comparable = &SemverValue{}
+var _ core.StarlarkValueToGoValueConversion = &SemverValue{}
+
+func (v *SemverValue) String() string         { return v.version.String() }
+func (v *SemverValue) Type() string           { return "semver.version" }
+func (v *SemverValue) Freeze()                {}
+func (v *SemverValue) Truth() starlark.Bool   { return true }
+func (v *SemverValue) AsGoValue() interface{} { return v.String() }
+
+func (v *SemverValue) Hash() (uint32, error) {
+	// Build metadata does not affect equality
+	segments := v.version.Segments64()
+	return hashBytes([]byte(fmt.Sprintf("%d.%d.%d-%s", segments[0], segments[1], segments[2], v.version.Prerelease()))), nil
+}
+
+func (v *SemverValue) CompareSameType(op syntax.Token, y starlark.Value, depth int) (bool, error) {
+	return compareResult(op, v.version.Compare(y.(*SemverValue).version))
+}
+
+func (v *SemverValue) Attr(name string) (starlark.Value, error) {
+	segments := v.version.Segments64()
+
+	switch name {
+	case "string":
+		return noArgsMethod(name, func() starlark.Value { return starlark.String(v.String()) }), nil
+	case "major":
+		return noArgsMethod(name, func() starlark.Value { return starlark.MakeInt64(segments[0]) }), nil
+	case "minor":
+		return noArgsMethod(name, func() starlark.Value { return starlark.MakeInt64(segments[1]) }), nil
+	case "patch":
+		return noArgsMethod(name, func() starlark.Value { return starlark.MakeInt64(segments[2]) }), nil
+	case "prerelease":
+		return noArgsMethod(name, func() starlark.Value { return starlark.String(v.version.Prerelease()) }), nil
+	case "metadata":
+		return noArgsMethod(name, func() starlark.Value { return starlark.String(v.version.Metadata()) }), nil
+	default:
+		return nil, nil
+	}
+}
+
+func (v *SemverValue) AttrNames() []string {
+	return []string{"major", "metadata", "minor", "patch", "prerelease", "string"}
+}
@@ -0,0 +1,39 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package yttlibrary
+
+import (
+	"crypto/sha1"
+	"fmt"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/k14s/ytt/pkg/template/core"
+)
+
+var (
+	SHA1API = starlark.StringDict{
+		"sha1": &starlarkstruct.Module{
+			Name: "sha1",
+			Members: starlark.StringDict{
+				"sum": starlark.NewBuiltin("sha1.sum", core.ErrWrapper(sha1Module{}.Sum)),
+			},
+		},
+	}
+)
+
+type sha1Module struct{}
+
+func (b sha1Module) Sum(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	val, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.String(fmt.Sprintf("%x", sha1.Sum([]byte(val)))), nil
+}
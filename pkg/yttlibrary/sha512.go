@@ -0,0 +1,39 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package yttlibrary
+
+import (
+	"crypto/sha512"
+	"fmt"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/k14s/ytt/pkg/template/core"
+)
+
+var (
+	SHA512API = starlark.StringDict{
+		"sha512": &starlarkstruct.Module{
+			Name: "sha512",
+			Members: starlark.StringDict{
+				"sum": starlark.NewBuiltin("sha512.sum", core.ErrWrapper(sha512Module{}.Sum)),
+			},
+		},
+	}
+)
+
+type sha512Module struct{}
+
+func (b sha512Module) Sum(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	val, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.String(fmt.Sprintf("%x", sha512.Sum512([]byte(val)))), nil
+}
@@ -5,10 +5,14 @@ package yttlibrary
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/k14s/ytt/pkg/orderedmap"
 	"github.com/k14s/ytt/pkg/template/core"
+	"github.com/k14s/ytt/pkg/yamlmeta"
 )
 
 var (
@@ -22,6 +26,11 @@ var (
 
 				"encode": starlark.NewBuiltin("struct.encode", core.ErrWrapper(structModule{}.Encode)),
 				"decode": starlark.NewBuiltin("struct.decode", core.ErrWrapper(structModule{}.Decode)),
+
+				"merge":     starlark.NewBuiltin("struct.merge", core.ErrWrapper(structModule{}.Merge)),
+				"get":       starlark.NewBuiltin("struct.get", core.ErrWrapper(structModule{}.Get)),
+				"set":       starlark.NewBuiltin("struct.set", core.ErrWrapper(structModule{}.Set)),
+				"keys_deep": starlark.NewBuiltin("struct.keys_deep", core.ErrWrapper(structModule{}.KeysDeep)),
 			},
 		},
 	}
@@ -90,3 +99,308 @@ func (b structModule) Decode(thread *starlark.Thread, f *starlark.Builtin,
 	val := core.NewStarlarkValue(args.Index(0)).AsGoValue()
 	return core.NewGoValue(val).AsStarlarkValue(), nil
 }
+
+// Merge returns copy of first value with keys of second value
+// added or replaced; with deep=True (default) nested maps are merged
+func (b structModule) Merge(thread *starlark.Thread, f *starlark.Builtin,
+	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+	if args.Len() != 2 {
+		return starlark.None, fmt.Errorf("expected exactly 2 arguments")
+	}
+
+	deep := true
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "deep":
+			val, err := core.NewStarlarkValue(kwarg[1]).AsBool()
+			if err != nil {
+				return starlark.None, fmt.Errorf("expected keyword argument 'deep' to be a boolean: %s", err)
+			}
+			deep = val
+		default:
+			return starlark.None, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
+	left, err := b.mapArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	right, err := b.mapArg(args.Index(1))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return b.asStarlarkValue(b.merge(left, right, deep), args.Index(0)), nil
+}
+
+// Get returns value found at path (e.g. 'a.b.c' or ["a", "b", "c"])
+// or default value if any of the path keys is not present
+func (b structModule) Get(thread *starlark.Thread, f *starlark.Builtin,
+	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+	if args.Len() != 2 {
+		return starlark.None, fmt.Errorf("expected exactly 2 arguments")
+	}
+
+	var defaultVal starlark.Value = starlark.None
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "default":
+			defaultVal = kwarg[1]
+		default:
+			return starlark.None, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
+	obj, err := b.mapArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	path, err := b.pathArg(args.Index(1))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	var val interface{} = obj
+
+	for _, key := range path {
+		var found bool
+		val, found = b.lookup(val, key)
+		if !found {
+			return defaultVal, nil
+		}
+	}
+
+	return b.asStarlarkValue(val, args.Index(0)), nil
+}
+
+// Set returns copy of a value with given value set at path;
+// missing intermediate maps are created
+func (b structModule) Set(thread *starlark.Thread, f *starlark.Builtin,
+	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+	if args.Len() != 3 {
+		return starlark.None, fmt.Errorf("expected exactly 3 arguments")
+	}
+
+	obj, err := b.mapArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	path, err := b.pathArg(args.Index(1))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	result, err := b.set(obj, nil, path, b.asGoValue(args.Index(2)))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return b.asStarlarkValue(result, args.Index(0)), nil
+}
+
+// KeysDeep returns dot-separated paths to all non-map values
+// (lists and empty maps are not descended into)
+func (b structModule) KeysDeep(thread *starlark.Thread, f *starlark.Builtin,
+	args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	obj, err := b.mapArg(args.Index(0))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	var result []starlark.Value
+
+	var collectKeys func(string, *orderedmap.Map)
+	collectKeys = func(prefix string, val *orderedmap.Map) {
+		val.Iterate(func(k, v interface{}) {
+			key := prefix + fmt.Sprintf("%v", k)
+			if typedVal, ok := v.(*orderedmap.Map); ok && typedVal.Len() > 0 {
+				collectKeys(key+".", typedVal)
+			} else {
+				result = append(result, starlark.String(key))
+			}
+		})
+	}
+
+	collectKeys("", obj)
+
+	return starlark.NewList(result), nil
+}
+
+func (b structModule) merge(left, right *orderedmap.Map, deep bool) *orderedmap.Map {
+	right.Iterate(func(k, v interface{}) {
+		if deep {
+			leftVal, _ := left.Get(k)
+			typedLeftVal, leftIsMap := leftVal.(*orderedmap.Map)
+			typedRightVal, rightIsMap := v.(*orderedmap.Map)
+
+			if leftIsMap && rightIsMap {
+				left.Set(k, b.merge(typedLeftVal, typedRightVal, deep))
+				return
+			}
+		}
+		left.Set(k, v)
+	})
+	return left
+}
+
+func (b structModule) set(obj interface{}, prefix, path []interface{}, val interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return val, nil
+	}
+
+	key := path[0]
+	keyPath := append(append([]interface{}{}, prefix...), key)
+
+	switch typedObj := obj.(type) {
+	case nil:
+		return b.set(orderedmap.NewMap(), prefix, path, val)
+
+	case *orderedmap.Map:
+		child, _ := typedObj.Get(key)
+
+		newChild, err := b.set(child, keyPath, path[1:], val)
+		if err != nil {
+			return nil, err
+		}
+
+		typedObj.Set(key, newChild)
+		return typedObj, nil
+
+	case []interface{}:
+		idx, ok := b.listIndex(typedObj, key)
+		if !ok {
+			return nil, fmt.Errorf("expected index '%v' to be within list at '%s' (length %d)",
+				key, b.pathStr(prefix), len(typedObj))
+		}
+
+		newChild, err := b.set(typedObj[idx], keyPath, path[1:], val)
+		if err != nil {
+			return nil, err
+		}
+
+		typedObj[idx] = newChild
+		return typedObj, nil
+
+	default:
+		return nil, fmt.Errorf("expected value at '%s' to be a map or list to set key '%v', but was %T",
+			b.pathStr(prefix), key, obj)
+	}
+}
+
+func (b structModule) lookup(obj interface{}, key interface{}) (interface{}, bool) {
+	switch typedObj := obj.(type) {
+	case *orderedmap.Map:
+		return typedObj.Get(key)
+
+	case []interface{}:
+		idx, ok := b.listIndex(typedObj, key)
+		if !ok {
+			return nil, false
+		}
+		return typedObj[idx], true
+
+	default:
+		return nil, false
+	}
+}
+
+// listIndex accepts both integers and numeric
+// strings (from dot-separated paths) as list indexes
+func (b structModule) listIndex(list []interface{}, key interface{}) (int, bool) {
+	var idx int64
+
+	switch typedKey := key.(type) {
+	case int64:
+		idx = typedKey
+	case string:
+		var err error
+		idx, err = strconv.ParseInt(typedKey, 10, 64)
+		if err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if idx < 0 || idx >= int64(len(list)) {
+		return 0, false
+	}
+	return int(idx), true
+}
+
+// mapArg accepts structs, dicts and YAML fragments
+// and returns a copy of their contents
+func (b structModule) mapArg(val starlark.Value) (*orderedmap.Map, error) {
+	typedVal, ok := b.asGoValue(val).(*orderedmap.Map)
+	if !ok {
+		return nil, fmt.Errorf("expected argument to be a struct or a dict, but was %s", val.Type())
+	}
+	return typedVal, nil
+}
+
+// pathArg accepts dot-separated string (e.g. 'a.b.c')
+// or a list of keys (useful when keys contain dots or are not strings)
+func (b structModule) pathArg(val starlark.Value) ([]interface{}, error) {
+	var path []interface{}
+
+	switch typedVal := val.(type) {
+	case starlark.String:
+		for _, piece := range strings.Split(string(typedVal), ".") {
+			path = append(path, piece)
+		}
+
+	case *starlark.List, starlark.Tuple:
+		path, _ = core.NewStarlarkValue(typedVal).AsGoValue().([]interface{})
+
+	default:
+		return nil, fmt.Errorf("expected path to be a string or a list, but was %s", val.Type())
+	}
+
+	if len(path) == 0 || (len(path) == 1 && path[0] == "") {
+		return nil, fmt.Errorf("expected path to be non-empty")
+	}
+
+	return path, nil
+}
+
+func (b structModule) pathStr(path []interface{}) string {
+	var pieces []string
+	for _, piece := range path {
+		pieces = append(pieces, fmt.Sprintf("%v", piece))
+	}
+	return strings.Join(pieces, ".")
+}
+
+func (b structModule) asGoValue(val starlark.Value) interface{} {
+	return yamlmeta.NewGoFromAST(core.NewStarlarkValue(val).AsGoValue())
+}
+
+// asStarlarkValue represents maps as structs only if original value was a struct
+func (b structModule) asStarlarkValue(val interface{}, original starlark.Value) starlark.Value {
+	var isStruct bool
+
+	switch original.(type) {
+	case *core.StarlarkStruct, *starlarkstruct.Struct:
+		isStruct = true
+	}
+
+	return core.NewGoValueWithOpts(val, core.GoValueOpts{MapIsStruct: isStruct}).AsStarlarkValue()
+}
@@ -0,0 +1,329 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package yttlibrary
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/k14s/starlark-go/syntax"
+	"github.com/k14s/ytt/pkg/template/core"
+)
+
+type TimeOpts struct {
+	// Now is returned by time.now(); nil indicates that time
+	// was not provided (templates should be reproducible by default)
+	Now            *time.Time
+	AllowWallClock bool
+}
+
+type TimeModule struct {
+	opts TimeOpts
+}
+
+func NewTimeModule(opts TimeOpts) TimeModule {
+	return TimeModule{opts}
+}
+
+func (b TimeModule) AsModule() starlark.StringDict {
+	return starlark.StringDict{
+		"time": &starlarkstruct.Module{
+			Name: "time",
+			Members: starlark.StringDict{
+				"now":            starlark.NewBuiltin("time.now", core.ErrWrapper(b.Now)),
+				"parse":          starlark.NewBuiltin("time.parse", core.ErrWrapper(b.Parse)),
+				"from_unix":      starlark.NewBuiltin("time.from_unix", core.ErrWrapper(b.FromUnix)),
+				"parse_duration": starlark.NewBuiltin("time.parse_duration", core.ErrWrapper(b.ParseDuration)),
+			},
+		},
+	}
+}
+
+func (b TimeModule) Now(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 0 {
+		return starlark.None, fmt.Errorf("expected no arguments")
+	}
+
+	switch {
+	case b.opts.Now != nil:
+		return &TimeValue{*b.opts.Now}, nil
+	case b.opts.AllowWallClock:
+		return &TimeValue{time.Now().UTC()}, nil
+	default:
+		return starlark.None, fmt.Errorf("expected time to be provided via --time flag or " +
+			"SOURCE_DATE_EPOCH env variable (hint: use --time-allow-wall-clock to use current time)")
+	}
+}
+
+// Parse uses Go reference time layout (e.g. '2006-01-02');
+// defaults to RFC3339 (e.g. '2006-01-02T15:04:05Z07:00')
+func (b TimeModule) Parse(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	val, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	layout := time.RFC3339
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "layout":
+			layout, err = core.NewStarlarkValue(kwarg[1]).AsString()
+			if err != nil {
+				return starlark.None, fmt.Errorf("expected keyword argument 'layout' to be a string: %s", err)
+			}
+		default:
+			return starlark.None, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
+	parsed, err := time.Parse(layout, val)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return &TimeValue{parsed}, nil
+}
+
+func (b TimeModule) FromUnix(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	secs, err := core.NewStarlarkValue(args.Index(0)).AsInt64()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return &TimeValue{time.Unix(secs, 0).UTC()}, nil
+}
+
+// ParseDuration accepts durations such as '1h30m', '90s' or '-1.5h'
+func (b TimeModule) ParseDuration(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	val, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	duration, err := time.ParseDuration(val)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return &DurationValue{duration}, nil
+}
+
+// TimeValue represents instant in time. Durations may be added
+// to or subtracted from it; subtracting times results in a duration.
+type TimeValue struct {
+	time time.Time
+}
+
+var _ starlark.HasAttrs = &TimeValue{}
+var _ starlark.HasBinary = &TimeValue{}
+var _ starlark.Comparable = &TimeValue{}
+var _ core.StarlarkValueToGoValueConversion = &TimeValue{}
+
+func (v *TimeValue) String() string         { return v.time.Format(time.RFC3339Nano) }
+func (v *TimeValue) Type() string           { return "time.time" }
+func (v *TimeValue) Freeze()                {}
+func (v *TimeValue) Truth() starlark.Bool   { return true }
+func (v *TimeValue) AsGoValue() interface{} { return v.String() }
+
+func (v *TimeValue) Hash() (uint32, error) {
+	// Same instant in different time zones is considered equal
+	return hashBytes([]byte(v.time.UTC().Format(time.RFC3339Nano))), nil
+}
+
+func (v *TimeValue) CompareSameType(op syntax.Token, y starlark.Value, depth int) (bool, error) {
+	other := y.(*TimeValue)
+
+	var result int
+	switch {
+	case v.time.Before(other.time):
+		result = -1
+	case v.time.After(other.time):
+		result = 1
+	}
+
+	return compareResult(op, result)
+}
+
+func (v *TimeValue) Binary(op syntax.Token, y starlark.Value, side starlark.Side) (starlark.Value, error) {
+	switch typedY := y.(type) {
+	case *DurationValue:
+		switch {
+		case op == syntax.PLUS:
+			return &TimeValue{v.time.Add(typedY.duration)}, nil
+		case op == syntax.MINUS && side == starlark.Left:
+			return &TimeValue{v.time.Add(-typedY.duration)}, nil
+		}
+	case *TimeValue:
+		if op == syntax.MINUS {
+			if side == starlark.Left {
+				return &DurationValue{v.time.Sub(typedY.time)}, nil
+			}
+			return &DurationValue{typedY.time.Sub(v.time)}, nil
+		}
+	}
+	return nil, nil // unhandled
+}
+
+func (v *TimeValue) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "string":
+		return noArgsMethod(name, func() starlark.Value { return starlark.String(v.String()) }), nil
+	case "format":
+		return starlark.NewBuiltin("time.time.format", core.ErrWrapper(v.format)), nil
+	case "unix":
+		return noArgsMethod(name, func() starlark.Value { return starlark.MakeInt64(v.time.Unix()) }), nil
+	case "utc":
+		return noArgsMethod(name, func() starlark.Value { return &TimeValue{v.time.UTC()} }), nil
+	case "year":
+		return noArgsMethod(name, func() starlark.Value { return starlark.MakeInt(v.time.Year()) }), nil
+	case "month":
+		return noArgsMethod(name, func() starlark.Value { return starlark.MakeInt(int(v.time.Month())) }), nil
+	case "day":
+		return noArgsMethod(name, func() starlark.Value { return starlark.MakeInt(v.time.Day()) }), nil
+	case "hour":
+		return noArgsMethod(name, func() starlark.Value { return starlark.MakeInt(v.time.Hour()) }), nil
+	case "minute":
+		return noArgsMethod(name, func() starlark.Value { return starlark.MakeInt(v.time.Minute()) }), nil
+	case "second":
+		return noArgsMethod(name, func() starlark.Value { return starlark.MakeInt(v.time.Second()) }), nil
+	case "weekday":
+		return noArgsMethod(name, func() starlark.Value { return starlark.String(v.time.Weekday().String()) }), nil
+	default:
+		return nil, nil
+	}
+}
+
+func (v *TimeValue) AttrNames() []string {
+	return []string{"day", "format", "hour", "minute", "month", "second", "string", "unix", "utc", "weekday", "year"}
+}
+
+func (v *TimeValue) format(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	layout, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.String(v.time.Format(layout)), nil
+}
+
+// DurationValue represents elapsed time (e.g. 1h30m0s).
+// Durations may be added together and multiplied by integers.
+type DurationValue struct {
+	duration time.Duration
+}
+
+var _ starlark.HasAttrs = &DurationValue{}
+var _ starlark.HasBinary = &DurationValue{}
+var _ starlark.Comparable = &DurationValue{}
+var _ core.StarlarkValueToGoValueConversion = &DurationValue{}
+
+func (v *DurationValue) String() string         { return v.duration.String() }
+func (v *DurationValue) Type() string           { return "time.duration" }
+func (v *DurationValue) Freeze()                {}
+func (v *DurationValue) Truth() starlark.Bool   { return v.duration != 0 }
+func (v *DurationValue) Hash() (uint32, error)  { return hashBytes([]byte(v.String())), nil }
+func (v *DurationValue) AsGoValue() interface{} { return v.String() }
+
+func (v *DurationValue) CompareSameType(op syntax.Token, y starlark.Value, depth int) (bool, error) {
+	other := y.(*DurationValue)
+
+	var result int
+	switch {
+	case v.duration < other.duration:
+		result = -1
+	case v.duration > other.duration:
+		result = 1
+	}
+
+	return compareResult(op, result)
+}
+
+func (v *DurationValue) Binary(op syntax.Token, y starlark.Value, side starlark.Side) (starlark.Value, error) {
+	switch typedY := y.(type) {
+	case *DurationValue:
+		left, right := v, typedY
+		if side == starlark.Right {
+			left, right = typedY, v
+		}
+
+		var result time.Duration
+
+		switch op {
+		case syntax.PLUS:
+			result = left.duration + right.duration
+			// Overflow wraps result around to the other side of the operand
+			if (right.duration > 0 && result < left.duration) || (right.duration < 0 && result > left.duration) {
+				return nil, fmt.Errorf("expected sum of durations %s and %s to fit into duration range", left.String(), right.String())
+			}
+		case syntax.MINUS:
+			result = left.duration - right.duration
+			if (right.duration > 0 && result > left.duration) || (right.duration < 0 && result < left.duration) {
+				return nil, fmt.Errorf("expected difference of durations %s and %s to fit into duration range", left.String(), right.String())
+			}
+		default:
+			return nil, nil // unhandled
+		}
+
+		return &DurationValue{result}, nil
+	case starlark.Int:
+		if op == syntax.STAR {
+			multiplier, ok := typedY.Int64()
+			if !ok {
+				return nil, fmt.Errorf("expected duration multiplier to fit into int64")
+			}
+			result := v.duration * time.Duration(multiplier)
+			// Overflow is detected by reversing multiplication (with exception
+			// of the only case that division does not detect: MinInt64 * -1)
+			if multiplier != 0 && (result/time.Duration(multiplier) != v.duration ||
+				(multiplier == -1 && v.duration == math.MinInt64)) {
+				return nil, fmt.Errorf("expected duration %s multiplied by %d to fit into duration range", v.String(), multiplier)
+			}
+			return &DurationValue{result}, nil
+		}
+	}
+	return nil, nil // unhandled
+}
+
+func (v *DurationValue) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "string":
+		return noArgsMethod(name, func() starlark.Value { return starlark.String(v.String()) }), nil
+	case "hours":
+		return noArgsMethod(name, func() starlark.Value { return starlark.Float(v.duration.Hours()) }), nil
+	case "minutes":
+		return noArgsMethod(name, func() starlark.Value { return starlark.Float(v.duration.Minutes()) }), nil
+	case "seconds":
+		return noArgsMethod(name, func() starlark.Value { return starlark.Float(v.duration.Seconds()) }), nil
+	case "milliseconds":
+		return noArgsMethod(name, func() starlark.Value { return starlark.MakeInt64(int64(v.duration / time.Millisecond)) }), nil
+	default:
+		return nil, nil
+	}
+}
+
+func (v *DurationValue) AttrNames() []string {
+	return []string{"hours", "milliseconds", "minutes", "seconds", "string"}
+}
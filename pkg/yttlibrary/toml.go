@@ -0,0 +1,61 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package yttlibrary
+
+import (
+	"fmt"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/k14s/ytt/pkg/template/core"
+	"github.com/k14s/ytt/pkg/toml"
+	"github.com/k14s/ytt/pkg/yamlmeta"
+)
+
+var (
+	TOMLAPI = starlark.StringDict{
+		"toml": &starlarkstruct.Module{
+			Name: "toml",
+			Members: starlark.StringDict{
+				"encode": starlark.NewBuiltin("toml.encode", core.ErrWrapper(tomlModule{}.Encode)),
+				"decode": starlark.NewBuiltin("toml.decode", core.ErrWrapper(tomlModule{}.Decode)),
+			},
+		},
+	}
+)
+
+type tomlModule struct{}
+
+func (b tomlModule) Encode(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	val := core.NewStarlarkValue(args.Index(0)).AsGoValue()
+
+	valBs, err := toml.Marshal(yamlmeta.NewGoFromAST(val))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.String(string(valBs)), nil
+}
+
+func (b tomlModule) Decode(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	valEncoded, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	valDecoded, err := toml.Unmarshal([]byte(valEncoded))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return core.NewGoValue(valDecoded).AsStarlarkValue(), nil
+}
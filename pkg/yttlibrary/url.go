@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"net/url"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/k14s/starlark-go/starlarkstruct"
@@ -27,11 +29,23 @@ var (
 
 				"query_params_encode": starlark.NewBuiltin("url.query_params_encode", core.ErrWrapper(urlModule{}.QueryParamsEncode)),
 				"query_params_decode": starlark.NewBuiltin("url.query_params_decode", core.ErrWrapper(urlModule{}.QueryParamsDecode)),
+
+				"parse":  starlark.NewBuiltin("url.parse", core.ErrWrapper(urlModule{}.Parse)),
+				"build":  starlark.NewBuiltin("url.build", core.ErrWrapper(urlModule{}.Build)),
+				"redact": starlark.NewBuiltin("url.redact", core.ErrWrapper(urlModule{}.Redact)),
 			},
 		},
 	}
 )
 
+const (
+	urlRedactedPassword = "xxxxx"
+)
+
+var (
+	urlPartNames = []string{"scheme", "opaque", "user", "password", "host", "port", "path", "raw_path", "query", "fragment"}
+)
+
 type urlModule struct{}
 
 func (b urlModule) PathSegmentEncode(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -101,11 +115,278 @@ func (b urlModule) QueryParamsEncode(thread *starlark.Thread, f *starlark.Builti
 		return starlark.None, fmt.Errorf("expected exactly one argument")
 	}
 
-	val := core.NewStarlarkValue(args.Index(0)).AsGoValue()
+	urlVals, err := b.queryValues(core.NewStarlarkValue(args.Index(0)).AsGoValue())
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.String(urlVals.Encode()), nil
+}
+
+func (b urlModule) QueryParamsDecode(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	encodedVal, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
+
+	urlVals, err := url.ParseQuery(encodedVal)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return b.queryValuesAsStarlark(urlVals), nil
+}
+
+// Parse returns struct with URL parts; user, password and port
+// are set to None when not present, query is decoded into a dict of lists,
+// raw_path keeps original encoding of path (e.g. '%2F' within a segment)
+func (b urlModule) Parse(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	val, err := core.NewStarlarkValue(args.Index(0)).AsString()
+	if err != nil {
+		return starlark.None, err
+	}
 
+	parsedURL, err := b.parseURL(val)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	urlVals, err := url.ParseQuery(parsedURL.RawQuery)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	var user, password, port starlark.Value = starlark.None, starlark.None, starlark.None
+
+	if parsedURL.User != nil {
+		user = starlark.String(parsedURL.User.Username())
+		if passwordStr, found := parsedURL.User.Password(); found {
+			password = starlark.String(passwordStr)
+		}
+	}
+
+	if portStr := parsedURL.Port(); len(portStr) > 0 {
+		portInt, err := strconv.Atoi(portStr)
+		if err != nil {
+			return starlark.None, fmt.Errorf("expected port '%s' to be a number", portStr)
+		}
+		port = starlark.MakeInt(portInt)
+	}
+
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"scheme":   starlark.String(parsedURL.Scheme),
+		"opaque":   starlark.String(parsedURL.Opaque),
+		"user":     user,
+		"password": password,
+		"host":     starlark.String(parsedURL.Hostname()),
+		"port":     port,
+		"path":     starlark.String(parsedURL.Path),
+		"raw_path": starlark.String(parsedURL.EscapedPath()),
+		"query":    b.queryValuesAsStarlark(urlVals),
+		"fragment": starlark.String(parsedURL.Fragment),
+	}), nil
+}
+
+// Build assembles URL from parts given as keyword arguments;
+// optionally parts could be taken from url.parse result
+// given as an argument (keyword arguments take precedence)
+func (b urlModule) Build(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() > 1 {
+		return starlark.None, fmt.Errorf("expected at most one argument")
+	}
+
+	builtURL, err := b.buildURL(args, kwargs)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return starlark.String(builtURL.String()), nil
+}
+
+// Redact returns URL with password replaced (if present)
+// so that URL could be safely included in logs or annotations
+func (b urlModule) Redact(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("expected exactly one argument")
+	}
+
+	var redactedURL *url.URL
+
+	if _, ok := args.Index(0).(*starlarkstruct.Struct); ok {
+		builtURL, err := b.buildURL(args, nil)
+		if err != nil {
+			return starlark.None, err
+		}
+		redactedURL = builtURL
+	} else {
+		val, err := core.NewStarlarkValue(args.Index(0)).AsString()
+		if err != nil {
+			return starlark.None, err
+		}
+
+		redactedURL, err = b.parseURL(val)
+		if err != nil {
+			return starlark.None, err
+		}
+	}
+
+	if redactedURL.User != nil {
+		if _, found := redactedURL.User.Password(); found {
+			redactedURL.User = url.UserPassword(redactedURL.User.Username(), urlRedactedPassword)
+		}
+	}
+
+	return starlark.String(redactedURL.String()), nil
+}
+
+func (b urlModule) buildURL(args starlark.Tuple, kwargs []starlark.Tuple) (*url.URL, error) {
+	parts := map[string]starlark.Value{}
+
+	if args.Len() == 1 {
+		typedStruct, ok := args.Index(0).(*starlarkstruct.Struct)
+		if !ok {
+			return nil, fmt.Errorf("expected argument to be a struct (e.g. from url.parse), but was %s", args.Index(0).Type())
+		}
+		for _, name := range urlPartNames {
+			val, err := typedStruct.Attr(name)
+			if err == nil && val != nil {
+				parts[name] = val
+			}
+		}
+	}
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+		if !b.isPartName(kwargName) {
+			return nil, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+		parts[kwargName] = kwarg[1]
+	}
+
+	// Explicitly given path takes precedence over
+	// raw_path that came from url.parse result
+	if b.hasKwarg(kwargs, "path") && !b.hasKwarg(kwargs, "raw_path") {
+		delete(parts, "raw_path")
+	}
+
+	strs := map[string]string{}
+	for _, name := range []string{"scheme", "opaque", "user", "password", "host", "path", "raw_path", "fragment"} {
+		val, found := parts[name]
+		if !found || val == starlark.None {
+			continue
+		}
+		str, err := core.NewStarlarkValue(val).AsString()
+		if err != nil {
+			return nil, fmt.Errorf("expected '%s' to be a string: %s", name, err)
+		}
+		strs[name] = str
+	}
+
+	result := &url.URL{
+		Scheme:   strs["scheme"],
+		Opaque:   strs["opaque"],
+		Host:     strs["host"],
+		Path:     strs["path"],
+		RawPath:  strs["raw_path"],
+		Fragment: strs["fragment"],
+	}
+
+	if rawPath, found := strs["raw_path"]; found {
+		path, err := url.PathUnescape(rawPath)
+		if err != nil {
+			return nil, fmt.Errorf("expected 'raw_path' to be an encoded path: %s", err)
+		}
+		if _, found := strs["path"]; !found {
+			result.Path = path
+		}
+	}
+
+	if strings.Contains(result.Host, ":") {
+		result.Host = "[" + result.Host + "]" // IPv6 address
+	}
+
+	if port, found := parts["port"]; found && port != starlark.None {
+		var portStr string
+
+		switch typedPort := port.(type) {
+		case starlark.Int:
+			portStr = typedPort.String()
+		case starlark.String:
+			portStr = string(typedPort)
+		default:
+			return nil, fmt.Errorf("expected 'port' to be an int, but was %s", port.Type())
+		}
+
+		if _, err := strconv.ParseUint(portStr, 10, 16); err != nil {
+			return nil, fmt.Errorf("expected 'port' to be a number between 0 and 65535, but was %s", port.String())
+		}
+
+		result.Host += ":" + portStr
+	}
+
+	if user, found := strs["user"]; found {
+		if password, found := strs["password"]; found {
+			result.User = url.UserPassword(user, password)
+		} else {
+			result.User = url.User(user)
+		}
+	} else if _, found := strs["password"]; found {
+		return nil, fmt.Errorf("expected 'user' to be specified when 'password' is specified")
+	}
+
+	if query, found := parts["query"]; found && query != starlark.None {
+		urlVals, err := b.queryValues(core.NewStarlarkValue(query).AsGoValue())
+		if err != nil {
+			return nil, fmt.Errorf("expected 'query' to be a dict of lists: %s", err)
+		}
+		result.RawQuery = urlVals.Encode()
+	}
+
+	return result, nil
+}
+
+func (b urlModule) parseURL(val string) (*url.URL, error) {
+	parsedURL, err := url.Parse(val)
+	if err != nil {
+		// Do not include URL itself in the error as it may contain a password
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("expected argument to be a valid URL: %s", err)
+	}
+	return parsedURL, nil
+}
+
+func (b urlModule) hasKwarg(kwargs []starlark.Tuple, name string) bool {
+	for _, kwarg := range kwargs {
+		if string(kwarg[0].(starlark.String)) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (b urlModule) isPartName(name string) bool {
+	for _, partName := range urlPartNames {
+		if partName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (b urlModule) queryValues(val interface{}) (url.Values, error) {
 	typedVal, ok := val.(*orderedmap.Map)
 	if !ok {
-		return starlark.None, fmt.Errorf("expected argument to be a map, but was %T", val)
+		return nil, fmt.Errorf("expected argument to be a map, but was %T", val)
 	}
 
 	urlVals := url.Values{}
@@ -136,27 +417,13 @@ func (b urlModule) QueryParamsEncode(thread *starlark.Thread, f *starlark.Builti
 		return nil
 	})
 	if err != nil {
-		return starlark.None, err
+		return nil, err
 	}
 
-	return starlark.String(urlVals.Encode()), nil
+	return urlVals, nil
 }
 
-func (b urlModule) QueryParamsDecode(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	if args.Len() != 1 {
-		return starlark.None, fmt.Errorf("expected exactly one argument")
-	}
-
-	encodedVal, err := core.NewStarlarkValue(args.Index(0)).AsString()
-	if err != nil {
-		return starlark.None, err
-	}
-
-	urlVals, err := url.ParseQuery(encodedVal)
-	if err != nil {
-		return starlark.None, err
-	}
-
+func (b urlModule) queryValuesAsStarlark(urlVals url.Values) starlark.Value {
 	result := orderedmap.NewMap()
 
 	for _, key := range b.sortedKeys(urlVals) {
@@ -167,7 +434,7 @@ func (b urlModule) QueryParamsDecode(thread *starlark.Thread, f *starlark.Builti
 		result.Set(key, val)
 	}
 
-	return core.NewGoValue(result).AsStarlarkValue(), nil
+	return core.NewGoValue(result).AsStarlarkValue()
 }
 
 func (b urlModule) sortedKeys(vals url.Values) []string {
@@ -21,6 +21,8 @@ const (
 )
 
 var (
+	semverRegexp = regexp.MustCompile(SemverRegex)
+
 	VersionAPI = starlark.StringDict{
 		"version": &starlarkstruct.Module{
 			Name: "version",
@@ -43,9 +45,9 @@ func (b versionModule) RequireAtLeast(thread *starlark.Thread, f *starlark.Built
 		return starlark.None, err
 	}
 
-	r := regexp.MustCompile(SemverRegex)
-	if !r.MatchString(val) {
-		return starlark.None, fmt.Errorf("version string '%s' must be a valid semver", val)
+	_, err = parseSemver(val)
+	if err != nil {
+		return starlark.None, err
 	}
 
 	userConstraint, err := semver.NewConstraint(">=" + val)
@@ -65,3 +67,12 @@ func (b versionModule) RequireAtLeast(thread *starlark.Thread, f *starlark.Built
 
 	return starlark.None, nil
 }
+
+// parseSemver parses strictly formatted semver (MAJOR.MINOR.PATCH
+// with optional pre-release and build metadata)
+func parseSemver(val string) (*semver.Version, error) {
+	if !semverRegexp.MatchString(val) {
+		return nil, fmt.Errorf("version string '%s' must be a valid semver", val)
+	}
+	return semver.NewVersion(val)
+}
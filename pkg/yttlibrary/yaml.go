@@ -5,11 +5,13 @@ package yttlibrary
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/k14s/starlark-go/starlarkstruct"
 	"github.com/k14s/ytt/pkg/template/core"
 	"github.com/k14s/ytt/pkg/yamlmeta"
+	"github.com/k14s/ytt/pkg/yamltemplate"
 )
 
 var (
@@ -31,6 +33,11 @@ func (b yamlModule) Encode(thread *starlark.Thread, f *starlark.Builtin, args st
 		return starlark.None, fmt.Errorf("expected exactly one argument")
 	}
 
+	allDocs, opts, err := b.encodeKwargs(kwargs)
+	if err != nil {
+		return starlark.None, err
+	}
+
 	val := core.NewStarlarkValue(args.Index(0)).AsGoValue()
 
 	var docSet *yamlmeta.DocumentSet
@@ -42,10 +49,34 @@ func (b yamlModule) Encode(thread *starlark.Thread, f *starlark.Builtin, args st
 		// Documents should be part of DocumentSet by the time it makes it here
 		panic("Unexpected document")
 	default:
-		docSet = &yamlmeta.DocumentSet{Items: []*yamlmeta.Document{{Value: typedVal}}}
+		if !allDocs {
+			docSet = &yamlmeta.DocumentSet{Items: []*yamlmeta.Document{{Value: typedVal}}}
+			break
+		}
+
+		var items []interface{}
+
+		switch typedItems := typedVal.(type) {
+		case []interface{}:
+			items = typedItems
+		case *yamlmeta.Array:
+			for _, item := range typedItems.Items {
+				items = append(items, item.Value)
+			}
+		default:
+			return starlark.None, fmt.Errorf("expected argument to be a list of documents "+
+				"when keyword argument 'all' is true, but was %T", typedVal)
+		}
+
+		docSet = &yamlmeta.DocumentSet{}
+		for _, item := range items {
+			docSet.Items = append(docSet.Items, &yamlmeta.Document{Value: item})
+		}
 	}
 
-	valBs, err := docSet.AsBytes()
+	valBs, err := docSet.AsBytesWithPrinter(func(w io.Writer) yamlmeta.DocumentPrinter {
+		return yamlmeta.NewYAMLPrinterWithOpts(w, opts)
+	})
 	if err != nil {
 		return starlark.None, err
 	}
@@ -63,6 +94,30 @@ func (b yamlModule) Decode(thread *starlark.Thread, f *starlark.Builtin, args st
 		return starlark.None, err
 	}
 
+	allDocs, err := b.allKwarg(kwargs)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	if allDocs {
+		docSet, err := yamlmeta.NewParser(yamlmeta.ParserOpts{WithoutMeta: true}).ParseBytes([]byte(valEncoded), "")
+		if err != nil {
+			return starlark.None, err
+		}
+
+		// Empty documents (e.g. due to trailing document separators) are not
+		// useful; documents with explicit values (including null) are kept
+		var nonEmptyItems []*yamlmeta.Document
+		for _, doc := range docSet.Items {
+			if doc.HasContent() {
+				nonEmptyItems = append(nonEmptyItems, doc)
+			}
+		}
+		docSet.Items = nonEmptyItems
+
+		return yamltemplate.NewStarlarkFragment(docSet), nil
+	}
+
 	var valDecoded interface{}
 
 	err = yamlmeta.PlainUnmarshal([]byte(valEncoded), &valDecoded)
@@ -72,3 +127,75 @@ func (b yamlModule) Decode(thread *starlark.Thread, f *starlark.Builtin, args st
 
 	return core.NewGoValue(valDecoded).AsStarlarkValue(), nil
 }
+
+// allKwarg parses 'all' keyword argument that indicates
+// that value is a set of documents instead of a single document
+func (b yamlModule) allKwarg(kwargs []starlark.Tuple) (bool, error) {
+	var allDocs bool
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "all":
+			val, err := b.allKwargValue(kwarg[1])
+			if err != nil {
+				return false, err
+			}
+			allDocs = val
+		default:
+			return false, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
+	return allDocs, nil
+}
+
+// encodeKwargs parses 'all' keyword argument as well as formatting options
+func (b yamlModule) encodeKwargs(kwargs []starlark.Tuple) (bool, yamlmeta.YAMLPrinterOpts, error) {
+	var allDocs bool
+	var opts yamlmeta.YAMLPrinterOpts
+
+	for _, kwarg := range kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+
+		switch kwargName {
+		case "all":
+			val, err := b.allKwargValue(kwarg[1])
+			if err != nil {
+				return false, opts, err
+			}
+			allDocs = val
+
+		case "indent":
+			indent, err := starlark.AsInt32(kwarg[1])
+			if err != nil {
+				return false, opts, fmt.Errorf("expected keyword argument 'indent' to be an int: %s", err)
+			}
+			if indent < 2 || indent > 9 {
+				return false, opts, fmt.Errorf("expected keyword argument 'indent' to be between 2 and 9, but was %d", indent)
+			}
+			opts.Indent = indent
+
+		case "sort_keys":
+			val, err := core.NewStarlarkValue(kwarg[1]).AsBool()
+			if err != nil {
+				return false, opts, fmt.Errorf("expected keyword argument 'sort_keys' to be a boolean: %s", err)
+			}
+			opts.SortKeys = val
+
+		default:
+			return false, opts, fmt.Errorf("Unexpected keyword argument '%s'", kwargName)
+		}
+	}
+
+	return allDocs, opts, nil
+}
+
+func (b yamlModule) allKwargValue(val starlark.Value) (bool, error) {
+	result, err := core.NewStarlarkValue(val).AsBool()
+	if err != nil {
+		return false, fmt.Errorf("expected keyword argument 'all' to be a boolean: %s", err)
+	}
+	return result, nil
+}